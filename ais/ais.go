@@ -0,0 +1,136 @@
+// Package ais converts between the 6 bit ASCII armored payloads of AIS NMEA
+// sentences and BitArray, and decodes the common AIS field types.
+package ais
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// ErrInvalidChar is returned for characters outside the armoring or 6 bit
+// text alphabets.
+var ErrInvalidChar = errors.New("invalid character")
+
+// Decode converts an armored payload with fill padding bits into a BitArray.
+func Decode(payload string, fill int) (*bitarray.BitArray, error) {
+	if fill < 0 || fill > 5 {
+		return nil, fmt.Errorf("invalid fill bits %d", fill)
+	}
+	out := bitarray.New()
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		var v byte
+		switch {
+		case c >= '0' && c <= 'W':
+			v = c - '0'
+		case c >= '`' && c <= 'w':
+			v = c - '`' + 40
+		default:
+			return nil, fmt.Errorf("%w: %q at %d", ErrInvalidChar, c, i)
+		}
+		out.AddN(uint(v), 6)
+	}
+	n := out.Len() - int64(fill)
+	if n < 0 {
+		return nil, fmt.Errorf("fill bits %d exceed payload", fill)
+	}
+	if fill == 0 {
+		return out, nil
+	}
+	if n == 0 {
+		return bitarray.New(), nil
+	}
+	return out.Slice(0, n)
+}
+
+// Encode converts ba into an armored payload, returning the number of fill
+// bits added to complete the last character.
+func Encode(ba *bitarray.BitArray) (string, int) {
+	var s strings.Builder
+	r := bitarray.NewReader(ba)
+	fill := 0
+	for r.Pos() < ba.Len() {
+		n := 6
+		if rem := ba.Len() - r.Pos(); rem < 6 {
+			n = int(rem)
+			fill = 6 - n
+		}
+		var v uint
+		r.ReadBits(&v, n)
+		v <<= uint(6 - n)
+		if v < 40 {
+			s.WriteByte(byte(v + '0'))
+		} else {
+			s.WriteByte(byte(v - 40 + '`'))
+		}
+	}
+	return s.String(), fill
+}
+
+// Text decodes chars 6 bit text characters from start, removing trailing
+// '@' padding and spaces.
+func Text(ba *bitarray.BitArray, start int64, chars int) (string, error) {
+	var s strings.Builder
+	for i := 0; i < chars; i++ {
+		v, err := ba.ReadUint(start+int64(i)*6, 6)
+		if err != nil {
+			return "", err
+		}
+		if v < 32 {
+			v += 64
+		}
+		s.WriteByte(byte(v))
+	}
+	return strings.TrimRight(s.String(), "@ "), nil
+}
+
+// AddText adds s as chars 6 bit text characters, padded with '@'.
+// Lower case letters are converted to upper case.
+func AddText(ba *bitarray.BitArray, s string, chars int) error {
+	if len(s) > chars {
+		return fmt.Errorf("%w: %q longer than %d characters", bitarray.ErrOverflow, s, chars)
+	}
+	s = strings.ToUpper(s)
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '_' {
+			return fmt.Errorf("%w: %q at %d", ErrInvalidChar, s[i], i)
+		}
+	}
+	s += strings.Repeat("@", chars-len(s))
+	for i := 0; i < len(s); i++ {
+		ba.AddN(uint(s[i]&0x3f), 6)
+	}
+	return nil
+}
+
+// Uint decodes an unsigned field of width bits from start.
+func Uint(ba *bitarray.BitArray, start int64, width int) (uint, error) {
+	return ba.ReadUint(start, int64(width))
+}
+
+// Int decodes a two's complement signed field of width bits from start.
+func Int(ba *bitarray.BitArray, start int64, width int) (int64, error) {
+	u, err := ba.ReadUint(start, int64(width))
+	if err != nil {
+		return 0, err
+	}
+	shift := uint(64 - width)
+	return int64(uint64(u)<<shift) >> shift, nil
+}
+
+// Lon decodes a 28 bit longitude in 1/10000 minutes from start as degrees.
+// 181 means not available.
+func Lon(ba *bitarray.BitArray, start int64) (float64, error) {
+	v, err := Int(ba, start, 28)
+	return float64(v) / 600000, err
+}
+
+// Lat decodes a 27 bit latitude in 1/10000 minutes from start as degrees.
+// 91 means not available.
+func Lat(ba *bitarray.BitArray, start int64) (float64, error) {
+	v, err := Int(ba, start, 27)
+	return float64(v) / 600000, err
+}
@@ -0,0 +1,123 @@
+package ais
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestDecode(t *testing.T) {
+	// Position report from the GPSD AIVDM documentation
+	ba, err := Decode("177KQJ5000G?tO`K>RA1wUbN0TKH", 0)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if ba.Len() != 168 {
+		t.Errorf("got len=%d, want 168", ba.Len())
+	}
+	uints := []struct {
+		name         string
+		start, width int
+		expected     uint
+	}{
+		{"type", 0, 6, 1},
+		{"mmsi", 8, 30, 477553000},
+		{"status", 38, 4, 5},
+		{"course", 116, 12, 510},
+		{"heading", 128, 9, 181},
+		{"second", 137, 6, 15},
+	}
+	for _, f := range uints {
+		actual, err := Uint(ba, int64(f.start), f.width)
+		if err != nil {
+			t.Fatalf("%s: failed with %q", f.name, err)
+		}
+		if actual != f.expected {
+			t.Errorf("%s: got %d, want %d", f.name, actual, f.expected)
+		}
+	}
+	lon, _ := Lon(ba, 61)
+	if math.Abs(lon-(-122.345832)) > 1e-5 {
+		t.Errorf("got lon=%f, want -122.345832", lon)
+	}
+	lat, _ := Lat(ba, 89)
+	if math.Abs(lat-47.582833) > 1e-5 {
+		t.Errorf("got lat=%f, want 47.582833", lat)
+	}
+
+	payload, fill := Encode(ba)
+	if payload != "177KQJ5000G?tO`K>RA1wUbN0TKH" || fill != 0 {
+		t.Errorf("got %q %d, want original payload", payload, fill)
+	}
+}
+
+func TestDecodeFill(t *testing.T) {
+	ba, err := Decode("w0", 2)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if expected := "[11111100 00------]"; ba.String() != expected {
+		t.Errorf("got %s, want %s", ba.String(), expected)
+	}
+	payload, fill := Encode(ba)
+	if payload != "w0" || fill != 2 {
+		t.Errorf("got %q %d, want w0 2", payload, fill)
+	}
+
+	if _, err := Decode("0X", 0); !errors.Is(err, ErrInvalidChar) {
+		t.Errorf("got %v, want %v", err, ErrInvalidChar)
+	}
+	if _, err := Decode("0", 6); err == nil {
+		t.Error("expected error for fill bits")
+	}
+}
+
+func TestText(t *testing.T) {
+	ba := bitarray.New()
+	ba.AddN(5, 6)
+	if err := AddText(ba, "Ever Given", 20); err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if ba.Len() != 126 {
+		t.Errorf("got len=%d, want 126", ba.Len())
+	}
+	actual, err := Text(ba, 6, 20)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual != "EVER GIVEN" {
+		t.Errorf("got %q, want %q", actual, "EVER GIVEN")
+	}
+
+	if err := AddText(ba, "toolong", 3); !errors.Is(err, bitarray.ErrOverflow) {
+		t.Errorf("got %v, want %v", err, bitarray.ErrOverflow)
+	}
+	if err := AddText(ba, "{}", 3); !errors.Is(err, ErrInvalidChar) {
+		t.Errorf("got %v, want %v", err, ErrInvalidChar)
+	}
+}
+
+func TestInt(t *testing.T) {
+	ba := bitarray.NewFromBytes([]byte{0xf0, 0x70}, 16)
+	tests := []struct {
+		start    int64
+		width    int
+		expected int64
+	}{
+		{0, 4, -1},
+		{0, 5, -2},
+		{8, 4, 7},
+		{4, 8, 7},
+	}
+	for _, tt := range tests {
+		actual, err := Int(ba, tt.start, tt.width)
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		if actual != tt.expected {
+			t.Errorf("%d+%d: got %d, want %d", tt.start, tt.width, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"encoding/asn1"
+	"errors"
+)
+
+// FromASN1 creates a BitArray from an ASN.1 BIT STRING. The bytes are copied.
+func FromASN1(bs asn1.BitString) *BitArray {
+	n := (bs.BitLength + 7) / 8
+	if n > len(bs.Bytes) {
+		n = len(bs.Bytes)
+	}
+	out := NewFromBytes(append([]byte(nil), bs.Bytes[:n]...), int64(bs.BitLength))
+	out.clearUnused()
+	return out
+}
+
+// ASN1 returns the BitArray as an ASN.1 BIT STRING. The bytes are copied and
+// unused bits of the last byte are zero.
+func (ba *BitArray) ASN1() asn1.BitString {
+	b := make([]byte, (ba.size+7)/8)
+	copy(b, ba.raw)
+	if r := ba.size % 8; r != 0 {
+		b[len(b)-1] &= byte(0xff << uint(8-r))
+	}
+	return asn1.BitString{Bytes: b, BitLength: int(ba.size)}
+}
+
+// MarshalDER returns the DER encoding of the BitArray as a BIT STRING.
+func (ba *BitArray) MarshalDER() ([]byte, error) {
+	return asn1.Marshal(ba.ASN1())
+}
+
+// MarshalDERNamed returns the DER encoding of the BitArray as a BIT STRING
+// with named bits, which strips all trailing zero bits.
+func (ba *BitArray) MarshalDERNamed() ([]byte, error) {
+	bs := ba.ASN1()
+	for bs.BitLength > 0 && bs.At(bs.BitLength-1) == 0 {
+		bs.BitLength--
+	}
+	bs.Bytes = bs.Bytes[:(bs.BitLength+7)/8]
+	return asn1.Marshal(bs)
+}
+
+// UnmarshalDER parses the DER encoding of a BIT STRING.
+func UnmarshalDER(b []byte) (*BitArray, error) {
+	var bs asn1.BitString
+	rest, err := asn1.Unmarshal(b, &bs)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after BIT STRING")
+	}
+	return FromASN1(bs), nil
+}
+
+// Zero the bits of the last byte beyond the size.
+func (ba *BitArray) clearUnused() {
+	if r := ba.size % 8; r != 0 && len(ba.raw) > 0 {
+		ba.raw[len(ba.raw)-1] &= byte(0xff << uint(8-r))
+	}
+}
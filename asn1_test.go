@@ -0,0 +1,104 @@
+package bitarray
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestASN1(t *testing.T) {
+	tests := map[string]struct {
+		in       asn1.BitString
+		expected string
+	}{
+		"empty":        {asn1.BitString{}, "[]"},
+		"partial":      {asn1.BitString{Bytes: []byte{0xb0}, BitLength: 4}, "[1011----]"},
+		"dirtyPadding": {asn1.BitString{Bytes: []byte{0xff, 0xff}, BitLength: 9}, "[11111111 1-------]"},
+		"extraBytes":   {asn1.BitString{Bytes: []byte{0x80, 0xff}, BitLength: 8}, "[10000000]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := FromASN1(tt.in)
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			bs := ba.ASN1()
+			if bs.BitLength != tt.in.BitLength {
+				t.Errorf("got length %d, want %d", bs.BitLength, tt.in.BitLength)
+			}
+			for i := 0; i < bs.BitLength; i++ {
+				if bs.At(i) != tt.in.At(i) {
+					t.Errorf("bit %d: got %d, want %d", i, bs.At(i), tt.in.At(i))
+				}
+			}
+		})
+	}
+}
+
+func TestASN1ClearsUnused(t *testing.T) {
+	ba := NewFromBytes([]byte{0xff}, 3)
+	bs := ba.ASN1()
+	if !bytes.Equal(bs.Bytes, []byte{0xe0}) {
+		t.Errorf("got %x, want e0", bs.Bytes)
+	}
+	if ba.Bytes()[0] != 0xff {
+		t.Error("expected BitArray to be unchanged")
+	}
+}
+
+func TestMarshalDER(t *testing.T) {
+	tests := map[string]struct {
+		ba       *BitArray
+		named    bool
+		expected []byte
+	}{
+		// X.690 8.6.4.2 example
+		"example":  {NewFromBytes([]byte{0x6e, 0x5d, 0xc0}, 18), false, []byte{0x03, 0x04, 0x06, 0x6e, 0x5d, 0xc0}},
+		"empty":    {New(), false, []byte{0x03, 0x01, 0x00}},
+		"zeros":    {NewFromBytes([]byte{0xa0}, 6), false, []byte{0x03, 0x02, 0x02, 0xa0}},
+		"named":    {NewFromBytes([]byte{0xa0}, 6), true, []byte{0x03, 0x02, 0x05, 0xa0}},
+		"namedAll": {NewFromBytes([]byte{0x00, 0x00}, 16), true, []byte{0x03, 0x01, 0x00}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var actual []byte
+			var err error
+			if tt.named {
+				actual, err = tt.ba.MarshalDERNamed()
+			} else {
+				actual, err = tt.ba.MarshalDER()
+			}
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if !bytes.Equal(actual, tt.expected) {
+				t.Errorf("got %x, want %x", actual, tt.expected)
+			}
+			ba, err := UnmarshalDER(actual)
+			if err != nil {
+				t.Fatalf("failed to unmarshal: %s", err)
+			}
+			if !tt.named && ba.String() != tt.ba.String() {
+				t.Errorf("got %s, want %s", ba, tt.ba)
+			}
+		})
+	}
+}
+
+func TestUnmarshalDERErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"padding":  {0x03, 0x02, 0x04, 0xa1},
+		"trailing": {0x03, 0x01, 0x00, 0x00},
+		"notBits":  {0x04, 0x01, 0x00},
+	}
+
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := UnmarshalDER(in); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidDigit is returned for characters or nibbles that are not decimal
+// digits.
+var ErrInvalidDigit = errors.New("invalid digit")
+
+const (
+	signPositive = 0xc
+	signNegative = 0xd
+)
+
+// AddBCD adds each decimal digit of digits as a 4 bit nibble.
+// Returns the number of bits added.
+func (ba *BitArray) AddBCD(digits string) (int, error) {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, fmt.Errorf("%w: %q at %d", ErrInvalidDigit, digits[i], i)
+		}
+	}
+	c := 0
+	for i := 0; i < len(digits); i++ {
+		c += ba.AddN(uint(digits[i]-'0'), 4)
+	}
+	return c, nil
+}
+
+// ReadBCD reads n decimal digits stored as 4 bit nibbles.
+func (r *Reader) ReadBCD(n int) (string, error) {
+	var s strings.Builder
+	for i := 0; i < n; i++ {
+		pos := r.Pos()
+		var d uint
+		if err := r.ReadBits(&d, 4); err != nil {
+			return "", err
+		}
+		if d > 9 {
+			return "", fmt.Errorf("%w: nibble %#x at bit %d", ErrInvalidDigit, d, pos)
+		}
+		s.WriteByte(byte('0' + d))
+	}
+	return s.String(), nil
+}
+
+// AddPackedDecimal adds v as digits BCD nibbles, left padded with zeros. When
+// signed a trailing sign nibble follows, 0xC for positive and 0xD for negative
+// values. Returns the number of bits added.
+func (ba *BitArray) AddPackedDecimal(v *big.Int, digits int, signed bool) (int, error) {
+	return ba.addPacked(v.Sign() < 0, new(big.Int).Abs(v).String(), digits, signed)
+}
+
+// AddPackedDecimalString adds the decimal string v, with an optional leading
+// '+' or '-', as AddPackedDecimal does. The sign of "-0" is kept.
+func (ba *BitArray) AddPackedDecimalString(v string, digits int, signed bool) (int, error) {
+	s := v
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, fmt.Errorf("%w: %q has no digits", ErrInvalidDigit, v)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, fmt.Errorf("%w: %q at %d", ErrInvalidDigit, s[i], len(v)-len(s)+i)
+		}
+	}
+	if trimmed := strings.TrimLeft(s, "0"); trimmed != "" {
+		s = trimmed
+	} else {
+		s = "0"
+	}
+	return ba.addPacked(neg, s, digits, signed)
+}
+
+// Add the digits of s with a sign nibble when signed.
+func (ba *BitArray) addPacked(neg bool, s string, digits int, signed bool) (int, error) {
+	if neg && !signed {
+		return 0, fmt.Errorf("%w: negative value -%s is unsigned", ErrOutOfRange, s)
+	}
+	if len(s) > digits {
+		return 0, fmt.Errorf("%w: %s has more than %d digits", ErrOverflow, s, digits)
+	}
+	c, err := ba.AddBCD(strings.Repeat("0", digits-len(s)) + s)
+	if err != nil {
+		return c, err
+	}
+	if signed {
+		if neg {
+			c += ba.AddN(signNegative, 4)
+		} else {
+			c += ba.AddN(signPositive, 4)
+		}
+	}
+	return c, nil
+}
+
+// ReadPackedDecimal reads a packed decimal of digits BCD nibbles, followed by
+// a sign nibble when signed. Sign nibbles 0xB and 0xD are negative, 0xA, 0xC,
+// 0xE and 0xF are positive.
+func (r *Reader) ReadPackedDecimal(digits int, signed bool) (*big.Int, error) {
+	s, err := r.ReadPackedDecimalString(digits, signed)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		// Only possible with zero digits
+		out = new(big.Int)
+	}
+	return out, nil
+}
+
+// ReadPackedDecimalString reads a packed decimal as ReadPackedDecimal does and
+// returns all digits, including leading zeros, with a leading '-' when
+// negative.
+func (r *Reader) ReadPackedDecimalString(digits int, signed bool) (string, error) {
+	s, err := r.ReadBCD(digits)
+	if err != nil {
+		return "", err
+	}
+	if !signed {
+		return s, nil
+	}
+	pos := r.Pos()
+	var sign uint
+	if err := r.ReadBits(&sign, 4); err != nil {
+		return "", err
+	}
+	switch sign {
+	case 0xa, 0xc, 0xe, 0xf:
+		return s, nil
+	case 0xb, 0xd:
+		return "-" + s, nil
+	default:
+		return "", fmt.Errorf("%w: sign nibble %#x at bit %d", ErrInvalidDigit, sign, pos)
+	}
+}
@@ -0,0 +1,171 @@
+package bitarray
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAddBCD(t *testing.T) {
+	tests := map[string]struct {
+		ba       *BitArray
+		in       string
+		expected string
+	}{
+		"digits":    {New(), "0123", "[00000001 00100011]"},
+		"odd":       {New(), "987", "[10011000 0111----]"},
+		"unaligned": {NewFromBytes([]byte{0x80}, 1), "59", "[10101100 1-------]"},
+		"empty":     {New(), "", "[]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := tt.ba.Len()
+			if _, err := tt.ba.AddBCD(tt.in); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := tt.ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			r := NewReader(tt.ba)
+			r.Seek(start, SeekStart)
+			actual, err := r.ReadBCD(len(tt.in))
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if actual != tt.in {
+				t.Errorf("got %q, want %q", actual, tt.in)
+			}
+		})
+	}
+
+	if _, err := New().AddBCD("12a"); !errors.Is(err, ErrInvalidDigit) {
+		t.Errorf("got %v, want %v", err, ErrInvalidDigit)
+	}
+}
+
+func TestReadBCDInvalid(t *testing.T) {
+	r := NewReader(NewFromBytes([]byte{0x1a}, 8))
+	_, err := r.ReadBCD(2)
+	if !errors.Is(err, ErrInvalidDigit) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidDigit)
+	}
+	if expected := "invalid digit: nibble 0xa at bit 4"; err.Error() != expected {
+		t.Errorf("got %q, want %q", err, expected)
+	}
+}
+
+func TestAddPackedDecimal(t *testing.T) {
+	tests := map[string]struct {
+		in       int64
+		digits   int
+		signed   bool
+		expected string
+	}{
+		"positive": {123, 5, true, "[00000000 00010010 00111100]"},
+		"negative": {-45, 3, true, "[00000100 01011101]"},
+		"unsigned": {45, 4, false, "[00000000 01000101]"},
+		"zero":     {0, 1, true, "[00001100]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			if _, err := ba.AddPackedDecimal(big.NewInt(tt.in), tt.digits, tt.signed); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			actual, err := NewReader(ba).ReadPackedDecimal(tt.digits, tt.signed)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if actual.Int64() != tt.in {
+				t.Errorf("got %s, want %d", actual, tt.in)
+			}
+		})
+	}
+}
+
+func TestPackedDecimalErrors(t *testing.T) {
+	if _, err := New().AddPackedDecimal(big.NewInt(1234), 3, true); !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v, want %v", err, ErrOverflow)
+	}
+	if _, err := New().AddPackedDecimal(big.NewInt(-1), 3, false); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+	// Alternate sign nibbles
+	v, err := NewReader(NewFromBytes([]byte{0x12, 0x3b}, 16)).ReadPackedDecimal(3, true)
+	if err != nil || v.Int64() != -123 {
+		t.Errorf("got %v %v, want -123", v, err)
+	}
+	_, err = NewReader(NewFromBytes([]byte{0x12, 0x39}, 16)).ReadPackedDecimal(3, true)
+	if !errors.Is(err, ErrInvalidDigit) {
+		t.Errorf("got %v, want %v", err, ErrInvalidDigit)
+	}
+}
+
+func TestAddPackedDecimalString(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		digits   int
+		signed   bool
+		expected string
+		read     string
+	}{
+		"leadingZeros": {"000123", 5, true, "[00000000 00010010 00111100]", "00123"},
+		"wideZeros":    {"00123", 3, true, "[00010010 00111100]", "123"},
+		"plus":         {"+45", 3, true, "[00000100 01011100]", "045"},
+		"minus":        {"-45", 3, true, "[00000100 01011101]", "-045"},
+		"negativeZero": {"-0", 1, true, "[00001101]", "-0"},
+		"unsigned":     {"0045", 4, false, "[00000000 01000101]", "0045"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			if _, err := ba.AddPackedDecimalString(tt.in, tt.digits, tt.signed); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			actual, err := NewReader(ba).ReadPackedDecimalString(tt.digits, tt.signed)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if actual != tt.read {
+				t.Errorf("got %q, want %q", actual, tt.read)
+			}
+		})
+	}
+}
+
+func TestAddPackedDecimalStringErrors(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		signed bool
+		err    error
+	}{
+		"letter":     {"12a", true, ErrInvalidDigit},
+		"empty":      {"", true, ErrInvalidDigit},
+		"signOnly":   {"-", true, ErrInvalidDigit},
+		"doubleSign": {"+-1", true, ErrInvalidDigit},
+		"space":      {" 12", true, ErrInvalidDigit},
+		"tooLong":    {"1234", true, ErrOverflow},
+		"unsigned":   {"-1", false, ErrOutOfRange},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			if _, err := ba.AddPackedDecimalString(tt.in, 3, tt.signed); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+			if ba.Len() != 0 {
+				t.Errorf("got len=%d, want 0", ba.Len())
+			}
+		})
+	}
+}
@@ -9,8 +9,10 @@ import (
 
 // BitArray holds an array of bits.
 type BitArray struct {
-	raw  []byte
-	size int64
+	raw      []byte
+	size     int64
+	overflow Overflow
+	err      error
 }
 
 // New creates a BitArray.
@@ -61,6 +63,24 @@ func NewFromBytes(b []byte, count int64) *BitArray {
 	return New(SetBytes(b), SetSize(count))
 }
 
+// NewFromString creates a BitArray from a string of '0' and '1' characters.
+// Spaces, brackets and '-' are ignored so the output of String is accepted.
+func NewFromString(s string) (*BitArray, error) {
+	out := New()
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0':
+			out.AddBit(0)
+		case '1':
+			out.AddBit(1)
+		case ' ', '[', ']', '-':
+		default:
+			return nil, fmt.Errorf("invalid bit %q at %d", s[i], i)
+		}
+	}
+	return out, nil
+}
+
 //const uintSize = 32 << (^uint(0) >> 63)
 
 // Bytes returns the BitArray as bytes.
@@ -137,6 +157,14 @@ func (ba *BitArray) Unset(n int64) {
 	ba.raw[idx] &^= byte(mask)
 }
 
+// Flip inverts the bit at position n.
+func (ba *BitArray) Flip(n int64) {
+	if n < 0 || n >= ba.size {
+		return
+	}
+	ba.raw[n/8] ^= byte(1 << uint(7-n%8))
+}
+
 // Pad array with n zeros.
 func (ba *BitArray) Pad(n uint) int {
 	c := 0
@@ -175,23 +203,56 @@ func (ba *BitArray) Add(u uint) int {
 
 // AddN adds a uint with a fixed width of n, left padded to width with zeros,
 // returns the number of bits added.
+// Values wider than width are handled by the configured overflow policy.
+// If the policy is OverflowError nothing is added and the error is recorded,
+// to be returned by Err.
 func (ba *BitArray) AddN(u uint, width int) int {
-	n := bits.Len(u)
-	if n > width {
-		// Truncate
-		return ba.Add(u >> (n - width))
-	}
-	c := ba.Pad(uint(width - n))
-	if n != 0 {
-		c += ba.Add(u)
+	c, err := ba.AddNPolicy(u, width, ba.overflow)
+	if err != nil && ba.err == nil {
+		ba.err = err
 	}
 	return c
 }
 
+// BitPacker is implemented by types that can pack themselves into a BitArray.
+type BitPacker interface {
+	PackBits(ba *BitArray) error
+}
+
 // Pack stuff together into existing array.
+// Integers are added with leading zeros removed, booleans as single bits,
+// strings and []byte as full 8 bit bytes and BitArrays verbatim.
+// Negative values, and uint64 values wider than a uint, are handled by the
+// configured overflow policy.
 func (ba *BitArray) Pack(fields ...interface{}) error {
+	return ba.PackPolicy(ba.overflow, fields...)
+}
+
+// PackPolicy packs stuff together into existing array, applying overflow
+// policy p to negative and oversized values.
+func (ba *BitArray) PackPolicy(p Overflow, fields ...interface{}) error {
 	for _, f := range fields {
 		switch c := f.(type) {
+		case BitPacker:
+			if err := c.PackBits(ba); err != nil {
+				return err
+			}
+		case bool:
+			ba.addBool(c)
+		case []bool:
+			for _, b := range c {
+				ba.addBool(b)
+			}
+		case string:
+			ba.addBytes([]byte(c))
+		case []byte:
+			ba.addBytes(c)
+		case BitArray:
+			ba.Append(c)
+		case *BitArray:
+			if c != nil {
+				ba.Append(*c)
+			}
 		case uint:
 			ba.Add(c)
 		case uint8:
@@ -201,16 +262,13 @@ func (ba *BitArray) Pack(fields ...interface{}) error {
 		case uint32:
 			ba.Add(uint(c))
 		case uint64:
-			// TODO check uintSize?
-			ba.Add(uint(c))
+			if err := ba.packUint64s(p, c); err != nil {
+				return err
+			}
 		case []uint:
 			for _, i := range c {
 				ba.Add(i)
 			}
-		case []uint8:
-			for _, i := range c {
-				ba.Add(uint(i))
-			}
 		case []uint16:
 			for _, i := range c {
 				ba.Add(uint(i))
@@ -220,42 +278,61 @@ func (ba *BitArray) Pack(fields ...interface{}) error {
 				ba.Add(uint(i))
 			}
 		case []uint64:
-			// TODO check uintSize?
-			for _, i := range c {
-				ba.Add(uint(i))
+			if err := ba.packUint64s(p, c...); err != nil {
+				return err
 			}
 		case int:
-			ba.Add(uint(c))
+			if err := ba.packInts(p, int64(c)); err != nil {
+				return err
+			}
 		case int8:
-			ba.Add(uint(c))
+			if err := ba.packInts(p, int64(c)); err != nil {
+				return err
+			}
 		case int16:
-			ba.Add(uint(c))
+			if err := ba.packInts(p, int64(c)); err != nil {
+				return err
+			}
 		case int32:
-			ba.Add(uint(c))
+			if err := ba.packInts(p, int64(c)); err != nil {
+				return err
+			}
 		case int64:
-			ba.Add(uint(c))
+			if err := ba.packInts(p, c); err != nil {
+				return err
+			}
 		case []int:
 			for _, i := range c {
-				ba.Add(uint(i))
+				if err := ba.packInts(p, int64(i)); err != nil {
+					return err
+				}
 			}
 		case []int8:
 			for _, i := range c {
-				ba.Add(uint(i))
+				if err := ba.packInts(p, int64(i)); err != nil {
+					return err
+				}
 			}
 		case []int16:
 			for _, i := range c {
-				ba.Add(uint(i))
+				if err := ba.packInts(p, int64(i)); err != nil {
+					return err
+				}
 			}
 		case []int32:
 			for _, i := range c {
-				ba.Add(uint(i))
+				if err := ba.packInts(p, int64(i)); err != nil {
+					return err
+				}
 			}
 		case []int64:
-			for _, i := range c {
-				ba.Add(uint(i))
+			if err := ba.packInts(p, c...); err != nil {
+				return err
 			}
 		case []interface{}:
-			return ba.Pack(c...)
+			if err := ba.PackPolicy(p, c...); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unable to pack %T", c)
 		}
@@ -263,6 +340,42 @@ func (ba *BitArray) Pack(fields ...interface{}) error {
 	return nil
 }
 
+func (ba *BitArray) addBool(b bool) {
+	if b {
+		ba.AddBit(1)
+	} else {
+		ba.AddBit(0)
+	}
+}
+
+func (ba *BitArray) addBytes(b []byte) {
+	for _, c := range b {
+		ba.AddN(uint(c), 8)
+	}
+}
+
+func (ba *BitArray) packInts(p Overflow, in ...int64) error {
+	for _, i := range in {
+		u, err := packInt(i, p)
+		if err != nil {
+			return err
+		}
+		ba.Add(u)
+	}
+	return nil
+}
+
+func (ba *BitArray) packUint64s(p Overflow, in ...uint64) error {
+	for _, i := range in {
+		u, err := packUint64(i, p)
+		if err != nil {
+			return err
+		}
+		ba.Add(u)
+	}
+	return nil
+}
+
 // Pack stuff together into a BitArray.
 func Pack(fields ...interface{}) (*BitArray, error) {
 	out := new(BitArray)
@@ -210,6 +210,55 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestFlip(t *testing.T) {
+	tests := map[string]struct {
+		ba       *BitArray
+		in       int64
+		expected string
+	}{
+		"set":      {NewFromBytes([]byte{0x00}, 8), 1, "[01000000]"},
+		"unset":    {NewFromBytes([]byte{0xff}, 8), 7, "[11111110]"},
+		"pastSize": {NewFromBytes([]byte{0x00}, 4), 4, "[0000----]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.ba.Flip(tt.in)
+			if actual := tt.ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewFromString(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		expected string
+	}{
+		"bits":    {"1011", "[1011----]"},
+		"grouped": {"10110000 1", "[10110000 1-------]"},
+		"layout":  {"[11110000 0001----]", "[11110000 0001----]"},
+		"empty":   {"", "[]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba, err := NewFromString(tt.in)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+
+	if _, err := NewFromString("10x1"); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestShiftL(t *testing.T) {
 	tests := map[string]struct {
 		ba       *BitArray
@@ -257,7 +306,7 @@ func TestPack(t *testing.T) {
 		{int16(108), "[1101100-]"},
 		{int32(108), "[1101100-]"},
 		{int64(108), "[1101100-]"},
-		{[]byte{0x6c}, "[1101100-]"},
+		{[]byte{0x6c}, "[01101100]"},
 		{[]uint{108}, "[1101100-]"},
 		{[]int{108}, "[1101100-]"},
 		{[]int8{108}, "[1101100-]"},
@@ -275,7 +324,7 @@ func TestPack(t *testing.T) {
 		{int16(0), "[0-------]"},
 		{int32(0), "[0-------]"},
 		{int64(0), "[0-------]"},
-		{[]byte{0}, "[0-------]"},
+		{[]byte{0}, "[00000000]"},
 		{[]uint{0}, "[0-------]"},
 		{[]int{0}, "[0-------]"},
 		{[]int8{0}, "[0-------]"},
@@ -290,11 +339,22 @@ func TestPack(t *testing.T) {
 		// Cases
 		{[]uint8{0xff, 0xff}, "[11111111 11111111]"},
 		{[]uint8{0xff, 0xf0}, "[11111111 11110000]"},
+		{[]uint8{0xff, 0x0f}, "[11111111 00001111]"},
 		{[]uint8{0xf0, 0xf0}, "[11110000 11110000]"},
-		{[]uint8{0xf0, 0xf0, 1}, "[11110000 11110000 1-------]"},
+		{[]uint8{0xf0, 0xf0, 1}, "[11110000 11110000 00000001]"},
 		{[]int{1, 128, 23}, "[11000000 010111--]"},
 		{[]int{1, 129, 23}, "[11000000 110111--]"},
 		{[]interface{}{uint8(0xff), 1, 2, 1, 4, 1, 1}, "[11111111 11011001 1-------]"},
+		// Bits and bytes
+		{true, "[1-------]"},
+		{false, "[0-------]"},
+		{[]bool{true, false, true}, "[101-----]"},
+		{"A", "[01000001]"},
+		{"", "[]"},
+		{*NewFromBytes([]byte{0x40}, 3), "[010-----]"},
+		{NewFromBytes([]byte{0x00, 0x80}, 9), "[00000000 1-------]"},
+		{[]interface{}{true, "a", NewFromBytes([]byte{0xc0}, 2)}, "[10110000 111-----]"},
+		{testPacker(5), "[00101---]"},
 	}
 
 	for _, tt := range tests {
@@ -314,6 +374,13 @@ func TestPack(t *testing.T) {
 	}
 }
 
+type testPacker uint
+
+func (p testPacker) PackBits(ba *BitArray) error {
+	ba.AddN(uint(p), 5)
+	return nil
+}
+
 func TestAppend(t *testing.T) {
 	tests := map[string]struct {
 		ba1      *BitArray
@@ -0,0 +1,177 @@
+// Package crc computes cyclic redundancy checks of any width up to 64 bits
+// over BitArray ranges of any length, following the Rocksoft model.
+package crc
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"src.userspace.com.au/bitarray"
+)
+
+var (
+	// ErrRange is returned for bit ranges outside the BitArray.
+	ErrRange = errors.New("bit range out of bounds")
+	// ErrParams is returned for invalid CRC parameters.
+	ErrParams = errors.New("invalid parameters")
+)
+
+// Params are the Rocksoft model parameters of a CRC.
+type Params struct {
+	Name  string
+	Width int
+	// Polynomial without the leading term, not reflected
+	Poly uint64
+	Init uint64
+	// Reflect each input byte, and a trailing partial byte within its width
+	RefIn bool
+	// Reflect the register before XorOut
+	RefOut bool
+	XorOut uint64
+	// CRC of the ASCII string "123456789"
+	Check uint64
+}
+
+// Standard presets, from the CRC RevEng catalogue.
+var (
+	CRC5USB      = Params{"CRC-5/USB", 5, 0x05, 0x1f, true, true, 0x1f, 0x19}
+	CRC5EPC      = Params{"CRC-5/EPC-C1G2", 5, 0x09, 0x09, false, false, 0x00, 0x00}
+	CRC8SMBus    = Params{"CRC-8/SMBUS", 8, 0x07, 0x00, false, false, 0x00, 0xf4}
+	CRC11FlexRay = Params{"CRC-11/FLEXRAY", 11, 0x385, 0x01a, false, false, 0x000, 0x5a3}
+	CRC15CAN     = Params{"CRC-15/CAN", 15, 0x4599, 0x0000, false, false, 0x0000, 0x059e}
+	CRC16IBM3740 = Params{"CRC-16/IBM-3740", 16, 0x1021, 0xffff, false, false, 0x0000, 0x29b1}
+	CRC16ARC     = Params{"CRC-16/ARC", 16, 0x8005, 0x0000, true, true, 0x0000, 0xbb3d}
+	CRC16Kermit  = Params{"CRC-16/KERMIT", 16, 0x1021, 0x0000, true, true, 0x0000, 0x2189}
+	CRC16XModem  = Params{"CRC-16/XMODEM", 16, 0x1021, 0x0000, false, false, 0x0000, 0x31c3}
+	CRC16Genibus = Params{"CRC-16/GENIBUS", 16, 0x1021, 0xffff, false, false, 0xffff, 0xd64e}
+	CRC24OpenPGP = Params{"CRC-24/OPENPGP", 24, 0x864cfb, 0xb704ce, false, false, 0x000000, 0x21cf02}
+	CRC32        = Params{"CRC-32/ISO-HDLC", 32, 0x04c11db7, 0xffffffff, true, true, 0xffffffff, 0xcbf43926}
+	CRC32BZip2   = Params{"CRC-32/BZIP2", 32, 0x04c11db7, 0xffffffff, false, false, 0xffffffff, 0xfc891918}
+	CRC32C       = Params{"CRC-32/ISCSI", 32, 0x1edc6f41, 0xffffffff, true, true, 0xffffffff, 0xe3069283}
+	CRC64XZ      = Params{"CRC-64/XZ", 64, 0x42f0e1eba9ea3693, 0xffffffffffffffff, true, true, 0xffffffffffffffff, 0x995dc9bbdf1939fa}
+	CRC64ECMA    = Params{"CRC-64/ECMA-182", 64, 0x42f0e1eba9ea3693, 0x0000000000000000, false, false, 0x0000000000000000, 0x6c40df5f0b497347}
+)
+
+// Catalogue lists the presets.
+var Catalogue = []Params{
+	CRC5USB, CRC5EPC, CRC8SMBus, CRC11FlexRay, CRC15CAN,
+	CRC16IBM3740, CRC16ARC, CRC16Kermit, CRC16XModem, CRC16Genibus,
+	CRC24OpenPGP, CRC32, CRC32BZip2, CRC32C, CRC64XZ, CRC64ECMA,
+}
+
+// CRC computes a CRC using a byte table. The register is held left aligned in
+// 64 bits so that every width shares the same engine.
+type CRC struct {
+	p     Params
+	shift uint
+	poly  uint64
+	table [256]uint64
+}
+
+// New creates a CRC for p. The width must be 1 to 64.
+func New(p Params) (*CRC, error) {
+	if p.Width < 1 || p.Width > 64 {
+		return nil, fmt.Errorf("%w: width %d", ErrParams, p.Width)
+	}
+	c := &CRC{p: p, shift: uint(64 - p.Width)}
+	c.poly = p.Poly << c.shift
+	for i := range c.table {
+		r := uint64(i) << 56
+		for j := 0; j < 8; j++ {
+			r = c.step(r, 0)
+		}
+		c.table[i] = r
+	}
+	return c, nil
+}
+
+// Params returns the parameters of the CRC.
+func (c *CRC) Params() Params {
+	return c.p
+}
+
+// Checksum returns the CRC of all bits in ba.
+func (c *CRC) Checksum(ba *bitarray.BitArray) uint64 {
+	sum, _ := c.ChecksumRange(ba, 0, ba.Len())
+	return sum
+}
+
+// ChecksumRange returns the CRC of length bits of ba from start.
+func (c *CRC) ChecksumRange(ba *bitarray.BitArray, start, length int64) (uint64, error) {
+	if start < 0 || length < 0 || start+length > ba.Len() {
+		return 0, fmt.Errorf("%w: %d+%d of %d", ErrRange, start, length, ba.Len())
+	}
+	r := c.p.Init << c.shift
+	if length > 0 {
+		s, err := ba.Slice(start, length)
+		if err != nil {
+			return 0, err
+		}
+		r = c.update(r, s.Bytes(), length)
+	}
+	if c.p.RefOut {
+		r = bits.Reverse64(r)
+	} else {
+		r >>= c.shift
+	}
+	return (r ^ c.p.XorOut) & mask(c.p.Width), nil
+}
+
+// Append adds the CRC of all bits in ba to the end of ba.
+func (c *CRC) Append(ba *bitarray.BitArray) uint64 {
+	sum := c.Checksum(ba)
+	ba.AddN(uint(sum), c.p.Width)
+	return sum
+}
+
+// Verify returns true if the last width bits of ba are the CRC of the bits
+// before them.
+func (c *CRC) Verify(ba *bitarray.BitArray) bool {
+	n := ba.Len() - int64(c.p.Width)
+	if n < 0 {
+		return false
+	}
+	sum, err := c.ChecksumRange(ba, 0, n)
+	if err != nil {
+		return false
+	}
+	v, err := ba.ReadUint(n, int64(c.p.Width))
+	return err == nil && uint64(v) == sum
+}
+
+// Process n bits of b, MSB first, into the left aligned register r.
+func (c *CRC) update(r uint64, b []byte, n int64) uint64 {
+	full := n / 8
+	for _, v := range b[:full] {
+		if c.p.RefIn {
+			v = bits.Reverse8(v)
+		}
+		r = c.table[byte(r>>56)^v] ^ r<<8
+	}
+	if rem := uint(n % 8); rem > 0 {
+		v := b[full] >> (8 - rem)
+		if c.p.RefIn {
+			v = bits.Reverse8(v) >> (8 - rem)
+		}
+		for i := int(rem) - 1; i >= 0; i-- {
+			r = c.step(r, uint64(v>>uint(i))&1)
+		}
+	}
+	return r
+}
+
+// Shift a single bit into the register.
+func (c *CRC) step(r, bit uint64) uint64 {
+	if (r>>63)^bit == 1 {
+		return r<<1 ^ c.poly
+	}
+	return r << 1
+}
+
+func mask(width int) uint64 {
+	if width == 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(width) - 1
+}
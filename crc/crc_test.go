@@ -0,0 +1,164 @@
+package crc
+
+import (
+	"errors"
+	"hash/crc32"
+	"hash/crc64"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func fromBytes(b []byte) *bitarray.BitArray {
+	return bitarray.NewFromBytes(b, int64(len(b)*8))
+}
+
+func TestCatalogue(t *testing.T) {
+	check := fromBytes([]byte("123456789"))
+	for _, p := range Catalogue {
+		t.Run(p.Name, func(t *testing.T) {
+			c, err := New(p)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := c.Checksum(check); actual != p.Check {
+				t.Errorf("got %#x, want %#x", actual, p.Check)
+			}
+		})
+	}
+}
+
+func TestStdlib(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	c32, _ := New(CRC32)
+	c32c, _ := New(CRC32C)
+	c64, _ := New(CRC64XZ)
+	c64ecma := crc64.MakeTable(crc64.ECMA)
+	castagnoli := crc32.MakeTable(crc32.Castagnoli)
+	for n := 0; n < 100; n++ {
+		b := make([]byte, n)
+		rnd.Read(b)
+		ba := fromBytes(b)
+		if actual, expected := c32.Checksum(ba), uint64(crc32.ChecksumIEEE(b)); actual != expected {
+			t.Errorf("crc32 len %d: got %#x, want %#x", n, actual, expected)
+		}
+		if actual, expected := c32c.Checksum(ba), uint64(crc32.Checksum(b, castagnoli)); actual != expected {
+			t.Errorf("crc32c len %d: got %#x, want %#x", n, actual, expected)
+		}
+		if actual, expected := c64.Checksum(ba), crc64.Checksum(b, c64ecma); actual != expected {
+			t.Errorf("crc64 len %d: got %#x, want %#x", n, actual, expected)
+		}
+	}
+}
+
+// Bit at a time Rocksoft model.
+func naive(p Params, ba *bitarray.BitArray, start, length int64) uint64 {
+	top := uint64(1) << uint(p.Width-1)
+	r := p.Init
+	for i := int64(0); i < length; i++ {
+		j := i
+		if p.RefIn {
+			// Reverse within each byte, or within the trailing partial byte
+			base := i - i%8
+			w := length - base
+			if w > 8 {
+				w = 8
+			}
+			j = base + w - 1 - i%8
+		}
+		var bit uint64
+		if ba.Test(start + j) {
+			bit = 1
+		}
+		if (r&top != 0) != (bit == 1) {
+			r = r<<1 ^ p.Poly
+		} else {
+			r <<= 1
+		}
+		r &= mask(p.Width)
+	}
+	if p.RefOut {
+		r = bits.Reverse64(r) >> uint(64-p.Width)
+	}
+	return r ^ p.XorOut
+}
+
+func TestChecksumRange(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	b := make([]byte, 40)
+	rnd.Read(b)
+	ba := fromBytes(b)
+	for _, p := range Catalogue {
+		c, err := New(p)
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		for i := 0; i < 50; i++ {
+			start := rnd.Int63n(ba.Len())
+			length := rnd.Int63n(ba.Len() - start)
+			actual, err := c.ChecksumRange(ba, start, length)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if expected := naive(p, ba, start, length); actual != expected {
+				t.Errorf("%s %d+%d: got %#x, want %#x", p.Name, start, length, actual, expected)
+			}
+		}
+	}
+
+	c, _ := New(CRC5USB)
+	if _, err := c.ChecksumRange(ba, 300, 30); !errors.Is(err, ErrRange) {
+		t.Errorf("got %v, want %v", err, ErrRange)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	tests := map[string]struct {
+		in *bitarray.BitArray
+		p  Params
+	}{
+		"epc":   {bitarray.NewFromBytes([]byte{0x30, 0x74, 0x25}, 22), CRC5EPC},
+		"can":   {bitarray.NewFromBytes([]byte{0x02, 0x46, 0x80}, 19), CRC15CAN},
+		"crc32": {bitarray.NewFromBytes([]byte{0xde, 0xad, 0xbe, 0xef}, 29), CRC32},
+		"crc64": {bitarray.NewFromBytes([]byte{0xde, 0xad, 0xbe, 0xef}, 32), CRC64ECMA},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(tt.p)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			n := tt.in.Len()
+			expected := c.Checksum(tt.in)
+			if actual := c.Append(tt.in); actual != expected {
+				t.Errorf("got %#x, want %#x", actual, expected)
+			}
+			if tt.in.Len() != n+int64(tt.p.Width) {
+				t.Errorf("got len=%d, want %d", tt.in.Len(), n+int64(tt.p.Width))
+			}
+			if v, _ := tt.in.ReadUint(n, int64(tt.p.Width)); uint64(v) != expected {
+				t.Errorf("got appended %#x, want %#x", v, expected)
+			}
+			if !c.Verify(tt.in) {
+				t.Error("expected valid CRC")
+			}
+			tt.in.Flip(3)
+			if c.Verify(tt.in) {
+				t.Error("expected invalid CRC")
+			}
+		})
+	}
+}
+
+func TestNewInvalidWidth(t *testing.T) {
+	for _, w := range []int{0, 65} {
+		p := CRC32
+		p.Width = w
+		if _, err := New(p); !errors.Is(err, ErrParams) {
+			t.Errorf("width %d: got %v, want %v", w, err, ErrParams)
+		}
+	}
+}
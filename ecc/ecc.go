@@ -0,0 +1,219 @@
+// Package ecc adds and checks parity bits and Hamming single error correcting
+// codes on BitArrays.
+package ecc
+
+import (
+	"errors"
+	"fmt"
+
+	"src.userspace.com.au/bitarray"
+)
+
+var (
+	// ErrParity is returned when a parity check fails.
+	ErrParity = errors.New("parity error")
+	// ErrUncorrectable is returned when a codeword has more errors than the
+	// code can correct.
+	ErrUncorrectable = errors.New("uncorrectable error")
+	// ErrLength is returned for input that is not a whole number of blocks.
+	ErrLength = errors.New("invalid length")
+	// ErrParams is returned for an invalid group or block size.
+	ErrParams = errors.New("invalid parameters")
+)
+
+// Parity is the parity of each group including its parity bit.
+type Parity int
+
+const (
+	// Even parity makes the number of ones in each group even.
+	Even Parity = iota
+	// Odd parity makes the number of ones in each group odd.
+	Odd
+)
+
+// AddParity returns ba with a parity bit after every group bits. A trailing
+// partial group also gets a parity bit.
+func AddParity(ba *bitarray.BitArray, group int, p Parity) (*bitarray.BitArray, error) {
+	if group < 1 {
+		return nil, fmt.Errorf("%w: group %d", ErrParams, group)
+	}
+	out := bitarray.New()
+	ones := 0
+	for i := int64(0); i < ba.Len(); i++ {
+		if ba.Test(i) {
+			out.AddBit(1)
+			ones++
+		} else {
+			out.AddBit(0)
+		}
+		if (i+1)%int64(group) == 0 || i == ba.Len()-1 {
+			out.AddBit(uint(ones+int(p)) & 1)
+			ones = 0
+		}
+	}
+	return out, nil
+}
+
+// CheckParity removes the parity bit following every group bits, as added by
+// AddParity. The returned error lists the bit positions of failing parity
+// bits, the data is returned regardless.
+func CheckParity(ba *bitarray.BitArray, group int, p Parity) (*bitarray.BitArray, error) {
+	if group < 1 {
+		return nil, fmt.Errorf("%w: group %d", ErrParams, group)
+	}
+	out := bitarray.New()
+	var bad []int64
+	ones := 0
+	n := 0
+	for i := int64(0); i < ba.Len(); i++ {
+		bit := ba.Test(i)
+		if bit {
+			ones++
+		}
+		if n == group || i == ba.Len()-1 {
+			if (ones+int(p))%2 != 0 {
+				bad = append(bad, i)
+			}
+			ones, n = 0, 0
+			continue
+		}
+		if bit {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+		n++
+	}
+	if len(bad) > 0 {
+		return out, fmt.Errorf("%w: at bits %v", ErrParity, bad)
+	}
+	return out, nil
+}
+
+// Hamming is a Hamming single error correcting code, optionally extended with
+// an overall parity bit for double error detection (SECDED). Codes with fewer
+// data bits than 2^r-r-1 are shortened by omitting the high data positions.
+//
+// Within a codeword, bit i is Hamming position i+1, check bits are at the
+// powers of two and the overall parity bit is last.
+type Hamming struct {
+	k, r     int
+	extended bool
+}
+
+// NewHamming creates a Hamming code for blocks of dataBits bits. For example,
+// NewHamming(4, false) is Hamming(7,4) and NewHamming(64, true) is
+// SECDED(72,64).
+func NewHamming(dataBits int, extended bool) (*Hamming, error) {
+	if dataBits < 1 {
+		return nil, fmt.Errorf("%w: %d data bits", ErrParams, dataBits)
+	}
+	r := 2
+	for 1<<uint(r)-r-1 < dataBits {
+		r++
+	}
+	return &Hamming{k: dataBits, r: r, extended: extended}, nil
+}
+
+// K returns the number of data bits per block.
+func (h *Hamming) K() int {
+	return h.k
+}
+
+// N returns the number of bits per codeword.
+func (h *Hamming) N() int {
+	if h.extended {
+		return h.k + h.r + 1
+	}
+	return h.k + h.r
+}
+
+// Encode returns the codewords for each block of K bits of data.
+func (h *Hamming) Encode(data *bitarray.BitArray) (*bitarray.BitArray, error) {
+	if data.Len()%int64(h.k) != 0 {
+		return nil, fmt.Errorf("%w: %d bits is not a multiple of %d", ErrLength, data.Len(), h.k)
+	}
+	out := bitarray.New()
+	block := make([]bool, h.k+h.r+1)
+	for start := int64(0); start < data.Len(); start += int64(h.k) {
+		// Place data at non power of two positions
+		j := start
+		syndrome := 0
+		for pos := 1; pos <= h.k+h.r; pos++ {
+			block[pos] = false
+			if pos&(pos-1) != 0 {
+				block[pos] = data.Test(j)
+				if block[pos] {
+					syndrome ^= pos
+				}
+				j++
+			}
+		}
+		// Each check bit clears its bit of the syndrome
+		ones := 0
+		for pos := 1; pos <= h.k+h.r; pos++ {
+			if pos&(pos-1) == 0 {
+				block[pos] = syndrome&pos != 0
+			}
+			if block[pos] {
+				out.AddBit(1)
+				ones++
+			} else {
+				out.AddBit(0)
+			}
+		}
+		if h.extended {
+			out.AddBit(uint(ones & 1))
+		}
+	}
+	return out, nil
+}
+
+// Decode corrects and returns the data of each codeword. It returns the
+// positions of corrected bits in code, and ErrUncorrectable for the first
+// block with uncorrectable errors.
+func (h *Hamming) Decode(code *bitarray.BitArray) (*bitarray.BitArray, []int64, error) {
+	n := int64(h.N())
+	if code.Len()%n != 0 {
+		return nil, nil, fmt.Errorf("%w: %d bits is not a multiple of %d", ErrLength, code.Len(), n)
+	}
+	out := bitarray.New()
+	var corrected []int64
+	for start := int64(0); start < code.Len(); start += n {
+		syndrome := 0
+		ones := 0
+		for i := int64(0); i < n; i++ {
+			if code.Test(start + i) {
+				ones++
+				if i < int64(h.k+h.r) {
+					syndrome ^= int(i) + 1
+				}
+			}
+		}
+		flip := 0
+		switch {
+		case syndrome == 0 && (!h.extended || ones%2 == 0):
+		case h.extended && ones%2 == 0:
+			return nil, corrected, fmt.Errorf("%w: double error in codeword at bit %d", ErrUncorrectable, start)
+		case syndrome > h.k+h.r:
+			return nil, corrected, fmt.Errorf("%w: syndrome %d in codeword at bit %d", ErrUncorrectable, syndrome, start)
+		case syndrome == 0:
+			// Only the overall parity bit is wrong
+			corrected = append(corrected, start+n-1)
+		default:
+			flip = syndrome
+			corrected = append(corrected, start+int64(syndrome)-1)
+		}
+		for pos := 1; pos <= h.k+h.r; pos++ {
+			if pos&(pos-1) == 0 {
+				continue
+			}
+			if code.Test(start+int64(pos)-1) != (pos == flip) {
+				out.AddBit(1)
+			} else {
+				out.AddBit(0)
+			}
+		}
+	}
+	return out, corrected, nil
+}
@@ -0,0 +1,189 @@
+package ecc
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestParity(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		group    int
+		p        Parity
+		expected string
+	}{
+		"even":    {"10110001", 4, Even, "1011100011"},
+		"odd":     {"10110001", 4, Odd, "1011000010"},
+		"partial": {"1011000", 4, Even, "101110000"},
+		"byte":    {"1101001", 7, Odd, "11010011"},
+		"empty":   {"", 4, Even, ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			expected, _ := bitarray.NewFromString(tt.expected)
+			out, err := AddParity(in, tt.group, tt.p)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+			data, err := CheckParity(out, tt.group, tt.p)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if data.String() != in.String() {
+				t.Errorf("got %s, want %s", data, in)
+			}
+		})
+	}
+
+	in, _ := bitarray.NewFromString("1011100010")
+	data, err := CheckParity(in, 4, Even)
+	if !errors.Is(err, ErrParity) {
+		t.Errorf("got %v, want %v", err, ErrParity)
+	}
+	if actual := data.String(); actual != "[10110001]" {
+		t.Errorf("got %s, want [10110001]", actual)
+	}
+}
+
+func TestParityInvalidGroup(t *testing.T) {
+	in, _ := bitarray.NewFromString("1011")
+	if _, err := AddParity(in, 0, Even); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+	if _, err := CheckParity(in, -1, Even); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+}
+
+func TestHamming74(t *testing.T) {
+	h, err := NewHamming(4, false)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if h.N() != 7 || h.K() != 4 {
+		t.Fatalf("got (%d,%d), want (7,4)", h.N(), h.K())
+	}
+	in, _ := bitarray.NewFromString("1011")
+	code, err := h.Encode(in)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := code.String(); actual != "[0110011-]" {
+		t.Errorf("got %s, want [0110011-]", actual)
+	}
+
+	for i := int64(0); i < 7; i++ {
+		code, _ := h.Encode(in)
+		code.Flip(i)
+		data, corrected, err := h.Decode(code)
+		if err != nil {
+			t.Fatalf("bit %d: failed with %q", i, err)
+		}
+		if actual := data.String(); actual != "[1011----]" {
+			t.Errorf("bit %d: got %s, want [1011----]", i, actual)
+		}
+		if !reflect.DeepEqual(corrected, []int64{i}) {
+			t.Errorf("bit %d: got corrected %v", i, corrected)
+		}
+	}
+}
+
+func TestHammingCodes(t *testing.T) {
+	tests := map[string]struct {
+		k        int
+		extended bool
+		n        int
+	}{
+		"hamming(15,11)":  {11, false, 15},
+		"shortened(12,8)": {8, false, 12},
+		"secded(8,4)":     {4, true, 8},
+		"secded(13,8)":    {8, true, 13},
+		"secded(72,64)":   {64, true, 72},
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, err := NewHamming(tt.k, tt.extended)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if h.N() != tt.n {
+				t.Fatalf("got n=%d, want %d", h.N(), tt.n)
+			}
+			// Three blocks of random data
+			data := bitarray.New()
+			for i := 0; i < 3*tt.k; i++ {
+				data.AddBit(uint(rnd.Intn(2)))
+			}
+			code, err := h.Encode(data)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if code.Len() != int64(3*tt.n) {
+				t.Fatalf("got len=%d, want %d", code.Len(), 3*tt.n)
+			}
+			out, corrected, err := h.Decode(code)
+			if err != nil || len(corrected) != 0 || out.String() != data.String() {
+				t.Fatalf("got %s %v %v, want %s", out, corrected, err, data)
+			}
+
+			// One error in each block
+			errs := []int64{int64(rnd.Intn(tt.n)), int64(tt.n + rnd.Intn(tt.n)), int64(3*tt.n - 1)}
+			for _, i := range errs {
+				code.Flip(i)
+			}
+			out, corrected, err = h.Decode(code)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if out.String() != data.String() {
+				t.Errorf("got %s, want %s", out, data)
+			}
+			if !reflect.DeepEqual(corrected, errs) {
+				t.Errorf("got corrected %v, want %v", corrected, errs)
+			}
+
+			if tt.extended {
+				for _, i := range errs {
+					code.Flip(i)
+				}
+				code.Flip(1)
+				code.Flip(2)
+				if _, _, err := h.Decode(code); !errors.Is(err, ErrUncorrectable) {
+					t.Errorf("got %v, want %v", err, ErrUncorrectable)
+				}
+			}
+		})
+	}
+}
+
+func TestHammingErrors(t *testing.T) {
+	if _, err := NewHamming(0, false); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+	h, _ := NewHamming(8, false)
+	short, _ := bitarray.NewFromString("101")
+	if _, err := h.Encode(short); !errors.Is(err, ErrLength) {
+		t.Errorf("got %v, want %v", err, ErrLength)
+	}
+	if _, _, err := h.Decode(short); !errors.Is(err, ErrLength) {
+		t.Errorf("got %v, want %v", err, ErrLength)
+	}
+	// Shortened (12,8), two errors giving a syndrome of 13
+	code, _ := h.Encode(bitarray.NewFromBytes([]byte{0}, 8))
+	code.Flip(0)
+	code.Flip(11)
+	if _, _, err := h.Decode(code); !errors.Is(err, ErrUncorrectable) {
+		t.Errorf("got %v, want %v", err, ErrUncorrectable)
+	}
+}
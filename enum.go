@@ -0,0 +1,81 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotInEnum is returned for names and codes missing from an Enum.
+var ErrNotInEnum = errors.New("not in enumeration")
+
+// Enum is an ordered table of names encoded as their index using the minimal
+// width for the table size.
+type Enum struct {
+	names []string
+	index map[string]int
+}
+
+// NewEnum creates an Enum from an ordered list of unique names.
+func NewEnum(names ...string) (*Enum, error) {
+	if len(names) == 0 {
+		return nil, errors.New("empty enumeration")
+	}
+	out := &Enum{
+		names: append([]string(nil), names...),
+		index: make(map[string]int, len(names)),
+	}
+	for i, n := range names {
+		if _, ok := out.index[n]; ok {
+			return nil, fmt.Errorf("duplicate enumeration name %q", n)
+		}
+		out.index[n] = i
+	}
+	return out, nil
+}
+
+// Len returns the number of names in the table.
+func (e *Enum) Len() int {
+	return len(e.names)
+}
+
+// Width returns the number of bits used to encode a name.
+func (e *Enum) Width() int {
+	return RangeWidth(0, int64(len(e.names)-1))
+}
+
+// Index returns the code for name.
+func (e *Enum) Index(name string) (int, bool) {
+	i, ok := e.index[name]
+	return i, ok
+}
+
+// Name returns the name for code i.
+func (e *Enum) Name(i int) (string, bool) {
+	if i < 0 || i >= len(e.names) {
+		return "", false
+	}
+	return e.names[i], true
+}
+
+// Encode adds the code for name to ba.
+func (e *Enum) Encode(ba *BitArray, name string) error {
+	i, ok := e.index[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotInEnum, name)
+	}
+	_, err := ba.AddRange(int64(i), 0, int64(len(e.names)-1))
+	return err
+}
+
+// Decode reads a code from r and returns its name.
+func (e *Enum) Decode(r *Reader) (string, error) {
+	pos := r.Pos()
+	i, err := r.ReadRange(0, int64(len(e.names)-1))
+	if errors.Is(err, ErrOutOfRange) {
+		return "", fmt.Errorf("%w: unknown code at bit %d: %s", ErrNotInEnum, pos, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return e.names[i], nil
+}
@@ -0,0 +1,72 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	e, err := NewEnum("passport", "id", "visa", "permit", "licence")
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if e.Width() != 3 {
+		t.Errorf("got width=%d, want 3", e.Width())
+	}
+
+	ba := New()
+	for _, n := range []string{"visa", "licence", "passport"} {
+		if err := e.Encode(ba, n); err != nil {
+			t.Fatalf("failed to encode %q: %s", n, err)
+		}
+	}
+	if expected := "[01010000 0-------]"; ba.String() != expected {
+		t.Errorf("got %s, want %s", ba.String(), expected)
+	}
+
+	r := NewReader(ba)
+	for _, expected := range []string{"visa", "licence", "passport"} {
+		actual, err := e.Decode(r)
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+		if actual != expected {
+			t.Errorf("got %q, want %q", actual, expected)
+		}
+	}
+
+	if err := e.Encode(ba, "unknown"); !errors.Is(err, ErrNotInEnum) {
+		t.Errorf("got %v, want %v", err, ErrNotInEnum)
+	}
+	// Code 7 is beyond the table
+	if _, err := e.Decode(NewReader(NewFromBytes([]byte{0xe0}, 3))); !errors.Is(err, ErrNotInEnum) {
+		t.Errorf("got %v, want %v", err, ErrNotInEnum)
+	}
+	// A 3 bit code truncated to 1 bit
+	if _, err := e.Decode(NewReader(NewFromBytes([]byte{0x80}, 1))); err != EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+}
+
+func TestEnumSingle(t *testing.T) {
+	e, _ := NewEnum("only")
+	ba := New()
+	if err := e.Encode(ba, "only"); err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if ba.Len() != 0 {
+		t.Errorf("got len=%d, want 0", ba.Len())
+	}
+	if actual, err := e.Decode(NewReader(ba)); err != nil || actual != "only" {
+		t.Errorf("got %q %v, want only", actual, err)
+	}
+}
+
+func TestNewEnumErrors(t *testing.T) {
+	if _, err := NewEnum(); err == nil {
+		t.Error("expected error for empty enumeration")
+	}
+	if _, err := NewEnum("a", "b", "a"); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+}
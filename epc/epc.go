@@ -0,0 +1,295 @@
+// Package epc encodes and decodes GS1 EPC binary tag encodings, as stored in
+// the EPC memory bank of RFID tags, to and from BitArray and their URIs.
+package epc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// ErrInvalid is returned for malformed URIs, fields and encodings.
+var ErrInvalid = errors.New("invalid EPC")
+
+// Scheme is an EPC binary encoding scheme.
+type Scheme int
+
+const (
+	// SGTIN96 is the 96 bit Serialised Global Trade Item Number.
+	SGTIN96 Scheme = iota
+	// SSCC96 is the 96 bit Serial Shipping Container Code.
+	SSCC96
+	// GIAI96 is the 96 bit Global Individual Asset Identifier.
+	GIAI96
+)
+
+type scheme struct {
+	header   uint
+	tag, id  string
+	refBits  [7]int
+	refTotal int // Company prefix and reference digits, 0 if the reference is an integer
+}
+
+var schemes = [...]scheme{
+	SGTIN96: {0x30, "sgtin-96", "sgtin", [7]int{4, 7, 10, 14, 17, 20, 24}, 13},
+	SSCC96:  {0x31, "sscc-96", "sscc", [7]int{18, 21, 24, 28, 31, 34, 38}, 17},
+	GIAI96:  {0x34, "giai-96", "giai", [7]int{42, 45, 48, 52, 55, 58, 62}, 0},
+}
+
+// Company prefix widths by partition value.
+var (
+	prefixBits   = [7]int{40, 37, 34, 30, 27, 24, 20}
+	prefixDigits = [7]int{12, 11, 10, 9, 8, 7, 6}
+)
+
+const (
+	headerBits = 8
+	serialBits = 38
+	ssccUnused = 24
+)
+
+// String returns the tag URI name of the scheme.
+func (s Scheme) String() string {
+	if s < 0 || int(s) >= len(schemes) {
+		return "unknown"
+	}
+	return schemes[s].tag
+}
+
+// Tag is a decoded EPC.
+type Tag struct {
+	Scheme Scheme
+	// Filter value, 0 to 7
+	Filter int
+	// Company prefix digits, 6 to 12 digits
+	CompanyPrefix string
+	// Indicator and item reference for SGTIN, extension and serial reference
+	// for SSCC, and the individual asset reference for GIAI.
+	Reference string
+	// Serial number for SGTIN
+	Serial string
+}
+
+// Encode returns the 96 bit binary encoding of t.
+func (t Tag) Encode() (*bitarray.BitArray, error) {
+	if t.Scheme < 0 || int(t.Scheme) >= len(schemes) {
+		return nil, fmt.Errorf("%w: unknown scheme %d", ErrInvalid, t.Scheme)
+	}
+	s := schemes[t.Scheme]
+	if t.Filter < 0 || t.Filter > 7 {
+		return nil, fmt.Errorf("%w: filter %d", ErrInvalid, t.Filter)
+	}
+	p := partition(len(t.CompanyPrefix))
+	if p < 0 {
+		return nil, fmt.Errorf("%w: company prefix %q", ErrInvalid, t.CompanyPrefix)
+	}
+	prefix, err := digits(t.CompanyPrefix, len(t.CompanyPrefix), prefixBits[p])
+	if err != nil {
+		return nil, err
+	}
+	refDigits := len(t.Reference)
+	if s.refTotal > 0 {
+		refDigits = s.refTotal - len(t.CompanyPrefix)
+	}
+	ref, err := digits(t.Reference, refDigits, s.refBits[p])
+	if err != nil {
+		return nil, err
+	}
+	if s.refTotal == 0 && len(t.Reference) > 1 && t.Reference[0] == '0' {
+		return nil, fmt.Errorf("%w: reference %q has leading zeros", ErrInvalid, t.Reference)
+	}
+
+	ba := bitarray.New()
+	ba.AddN(s.header, headerBits)
+	ba.AddN(uint(t.Filter), 3)
+	ba.AddN(uint(p), 3)
+	ba.AddN(prefix, prefixBits[p])
+	ba.AddN(ref, s.refBits[p])
+	switch t.Scheme {
+	case SGTIN96:
+		if len(t.Serial) > 1 && t.Serial[0] == '0' {
+			return nil, fmt.Errorf("%w: serial %q has leading zeros", ErrInvalid, t.Serial)
+		}
+		serial, err := digits(t.Serial, len(t.Serial), serialBits)
+		if err != nil {
+			return nil, err
+		}
+		ba.AddN(serial, serialBits)
+	case SSCC96:
+		ba.AddN(0, ssccUnused)
+	}
+	return ba, nil
+}
+
+// Decode decodes a 96 bit binary encoding from the start of ba.
+func Decode(ba *bitarray.BitArray) (Tag, error) {
+	var t Tag
+	if ba.Len() < 96 {
+		return t, fmt.Errorf("%w: %d bits, want 96", ErrInvalid, ba.Len())
+	}
+	r := bitarray.NewReader(ba)
+	var header, filter, p uint
+	if err := r.ReadBits(&header, headerBits); err != nil {
+		return t, err
+	}
+	t.Scheme = -1
+	for i, s := range schemes {
+		if s.header == header {
+			t.Scheme = Scheme(i)
+		}
+	}
+	if t.Scheme < 0 {
+		return t, fmt.Errorf("%w: unsupported header %#02x", ErrInvalid, header)
+	}
+	s := schemes[t.Scheme]
+	if err := r.ReadBits(&filter, 3); err != nil {
+		return t, err
+	}
+	t.Filter = int(filter)
+	if err := r.ReadBits(&p, 3); err != nil {
+		return t, err
+	}
+	if p > 6 {
+		return t, fmt.Errorf("%w: partition %d", ErrInvalid, p)
+	}
+	var prefix, ref uint
+	if err := r.ReadBits(&prefix, prefixBits[p]); err != nil {
+		return t, err
+	}
+	if err := r.ReadBits(&ref, s.refBits[p]); err != nil {
+		return t, err
+	}
+	var err error
+	if t.CompanyPrefix, err = format(prefix, prefixDigits[p]); err != nil {
+		return t, err
+	}
+	if s.refTotal > 0 {
+		t.Reference, err = format(ref, s.refTotal-prefixDigits[p])
+	} else {
+		t.Reference, err = format(ref, 0)
+	}
+	if err != nil {
+		return t, err
+	}
+	switch t.Scheme {
+	case SGTIN96:
+		var serial uint
+		if err := r.ReadBits(&serial, serialBits); err != nil {
+			return t, err
+		}
+		t.Serial, _ = format(serial, 0)
+	case SSCC96:
+		var unused uint
+		if err := r.ReadBits(&unused, ssccUnused); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// ParseURI parses an EPC tag URI, such as urn:epc:tag:sgtin-96:3.0614141.812345.6789,
+// or a pure identity URI, such as urn:epc:id:sgtin:0614141.812345.6789. Pure
+// identity URIs have a filter of 0.
+func ParseURI(uri string) (Tag, error) {
+	var t Tag
+	parts := strings.Split(uri, ":")
+	if len(parts) != 5 || parts[0] != "urn" || parts[1] != "epc" {
+		return t, fmt.Errorf("%w: URI %q", ErrInvalid, uri)
+	}
+	t.Scheme = -1
+	for i, s := range schemes {
+		if (parts[2] == "tag" && parts[3] == s.tag) || (parts[2] == "id" && parts[3] == s.id) {
+			t.Scheme = Scheme(i)
+		}
+	}
+	if t.Scheme < 0 {
+		return t, fmt.Errorf("%w: unsupported URI %q", ErrInvalid, uri)
+	}
+	fields := strings.Split(parts[4], ".")
+	if parts[2] == "tag" {
+		f, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields[0]) != 1 {
+			return t, fmt.Errorf("%w: filter %q", ErrInvalid, fields[0])
+		}
+		t.Filter = f
+		fields = fields[1:]
+	}
+	n := 2
+	if t.Scheme == SGTIN96 {
+		n = 3
+	}
+	if len(fields) != n {
+		return t, fmt.Errorf("%w: URI %q", ErrInvalid, uri)
+	}
+	t.CompanyPrefix = fields[0]
+	t.Reference = fields[1]
+	if n == 3 {
+		t.Serial = fields[2]
+	}
+	return t, nil
+}
+
+// TagURI returns the EPC tag URI of t.
+func (t Tag) TagURI() string {
+	return fmt.Sprintf("urn:epc:tag:%s:%d.%s", t.Scheme, t.Filter, t.fields())
+}
+
+// IDURI returns the EPC pure identity URI of t.
+func (t Tag) IDURI() string {
+	id := "unknown"
+	if t.Scheme >= 0 && int(t.Scheme) < len(schemes) {
+		id = schemes[t.Scheme].id
+	}
+	return fmt.Sprintf("urn:epc:id:%s:%s", id, t.fields())
+}
+
+func (t Tag) fields() string {
+	out := t.CompanyPrefix + "." + t.Reference
+	if t.Scheme == SGTIN96 {
+		out += "." + t.Serial
+	}
+	return out
+}
+
+// Return the partition value for a company prefix of n digits, or -1.
+func partition(n int) int {
+	for p, d := range prefixDigits {
+		if d == n {
+			return p
+		}
+	}
+	return -1
+}
+
+// Parse a decimal string of n digits that must fit in width bits.
+func digits(s string, n, width int) (uint, error) {
+	if len(s) != n || n == 0 {
+		return 0, fmt.Errorf("%w: %q is not %d digits", ErrInvalid, s, n)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, fmt.Errorf("%w: %q is not numeric", ErrInvalid, s)
+		}
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || v >= 1<<uint(width) {
+		return 0, fmt.Errorf("%w: %q does not fit %d bits", bitarray.ErrOverflow, s, width)
+	}
+	return uint(v), nil
+}
+
+// Format v as decimal, zero padded to n digits. With n of 0 there is no
+// padding.
+func format(v uint, n int) (string, error) {
+	s := strconv.FormatUint(uint64(v), 10)
+	if n == 0 {
+		return s, nil
+	}
+	if len(s) > n {
+		return "", fmt.Errorf("%w: %d exceeds %d digits", ErrInvalid, v, n)
+	}
+	return strings.Repeat("0", n-len(s)) + s, nil
+}
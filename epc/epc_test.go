@@ -0,0 +1,144 @@
+package epc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestEncode(t *testing.T) {
+	tests := map[string]struct {
+		uri      string
+		expected string
+	}{
+		"sgtin": {"urn:epc:tag:sgtin-96:3.0614141.812345.6789", "3074257BF7194E4000001A85"},
+		"sscc":  {"urn:epc:tag:sscc-96:3.0614141.1234567890", "3174257BF4499602D2000000"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, err := ParseURI(tt.uri)
+			if err != nil {
+				t.Fatalf("failed to parse: %s", err)
+			}
+			ba, err := tag.Encode()
+			if err != nil {
+				t.Fatalf("failed to encode: %s", err)
+			}
+			if actual := fmt.Sprintf("%X", ba.Bytes()); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			decoded, err := Decode(ba)
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if actual := decoded.TagURI(); actual != tt.uri {
+				t.Errorf("got %s, want %s", actual, tt.uri)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"sgtinP0":   "urn:epc:tag:sgtin-96:1.061414100000.0.274877906943",
+		"sgtinP6":   "urn:epc:tag:sgtin-96:0.061414.1234567.0",
+		"ssccP6":    "urn:epc:tag:sscc-96:7.061414.00000012345",
+		"giaiP0":    "urn:epc:tag:giai-96:3.061414100000.4398046511103",
+		"giaiP5":    "urn:epc:tag:giai-96:3.0614141.12345400",
+		"giaiP6Max": "urn:epc:tag:giai-96:0.061414.4611686018427387903",
+	}
+
+	for name, uri := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, err := ParseURI(uri)
+			if err != nil {
+				t.Fatalf("failed to parse: %s", err)
+			}
+			ba, err := tag.Encode()
+			if err != nil {
+				t.Fatalf("failed to encode: %s", err)
+			}
+			if ba.Len() != 96 {
+				t.Errorf("got len=%d, want 96", ba.Len())
+			}
+			decoded, err := Decode(ba)
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if decoded != tag {
+				t.Errorf("got %+v, want %+v", decoded, tag)
+			}
+		})
+	}
+}
+
+func TestIDURI(t *testing.T) {
+	tag, err := ParseURI("urn:epc:id:sgtin:0614141.812345.6789")
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	expected := Tag{Scheme: SGTIN96, CompanyPrefix: "0614141", Reference: "812345", Serial: "6789"}
+	if tag != expected {
+		t.Errorf("got %+v, want %+v", tag, expected)
+	}
+	if actual := tag.IDURI(); actual != "urn:epc:id:sgtin:0614141.812345.6789" {
+		t.Errorf("got %s", actual)
+	}
+	if actual := tag.TagURI(); actual != "urn:epc:tag:sgtin-96:0.0614141.812345.6789" {
+		t.Errorf("got %s", actual)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := map[string]struct {
+		uri string
+		err error
+	}{
+		"scheme":        {"urn:epc:tag:sgtin-198:3.0614141.812345.6789", ErrInvalid},
+		"fields":        {"urn:epc:tag:sgtin-96:3.0614141.812345", ErrInvalid},
+		"filter":        {"urn:epc:tag:sgtin-96:8.0614141.812345.6789", ErrInvalid},
+		"prefixLen":     {"urn:epc:tag:sgtin-96:3.06141.8123456.6789", ErrInvalid},
+		"referenceLen":  {"urn:epc:tag:sgtin-96:3.0614141.81234.6789", ErrInvalid},
+		"notNumeric":    {"urn:epc:tag:sgtin-96:3.0614141.81234x.6789", ErrInvalid},
+		"serialZeros":   {"urn:epc:tag:sgtin-96:3.0614141.812345.06789", ErrInvalid},
+		"serialTooWide": {"urn:epc:tag:sgtin-96:3.0614141.812345.274877906944", bitarray.ErrOverflow},
+		"giaiTooWide":   {"urn:epc:tag:giai-96:3.061414100000.4398046511104", bitarray.ErrOverflow},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, err := ParseURI(tt.uri)
+			if err == nil {
+				_, err = tag.Encode()
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := map[string]struct {
+		in  []byte
+		err error
+	}{
+		"header":    {[]byte{0x35, 0x74, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ErrInvalid},
+		"partition": {[]byte{0x30, 0x7c, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ErrInvalid},
+		// Company prefix 2^40-1 has 13 digits
+		"prefix": {[]byte{0x30, 0x63, 0xff, 0xff, 0xff, 0xff, 0xfc, 0, 0, 0, 0, 0}, ErrInvalid},
+		"short":  {[]byte{0x30, 0x74, 0x25}, ErrInvalid},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(bitarray.NewFromBytes(tt.in, int64(len(tt.in)*8)))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
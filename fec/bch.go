@@ -0,0 +1,139 @@
+package fec
+
+import (
+	"fmt"
+	"sort"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// BCH is a systematic narrow-sense binary BCH code of n bits, correcting up to
+// t bit errors. Codes shorter than 2^m-1 are shortened. The first bit of a
+// codeword is the highest degree.
+type BCH struct {
+	f       *Field
+	n, k, t int
+	// Generator polynomial, lowest degree first
+	gen []uint
+}
+
+// NewBCH creates a code of n bits over f whose generator polynomial is the
+// least common multiple of the minimal polynomials of alpha^1 to alpha^2t.
+func NewBCH(f *Field, n, t int) (*BCH, error) {
+	if n > f.q-1 || t < 1 || 2*t >= f.q-1 {
+		return nil, fmt.Errorf("%w: BCH n=%d t=%d over GF(2^%d)", ErrParams, n, t, f.m)
+	}
+	gen := []uint{1}
+	seen := make([]bool, f.q-1)
+	for i := 1; i <= 2*t; i++ {
+		if seen[i] {
+			continue
+		}
+		// Minimal polynomial from the conjugates alpha^(i*2^j)
+		for c := i; !seen[c]; c = c * 2 % (f.q - 1) {
+			seen[c] = true
+			gen = f.polyMul(gen, []uint{f.Exp(c), 1})
+		}
+	}
+	k := n - (len(gen) - 1)
+	if k < 1 {
+		return nil, fmt.Errorf("%w: BCH n=%d t=%d has no data bits", ErrParams, n, t)
+	}
+	return &BCH{f: f, n: n, k: k, t: t, gen: gen}, nil
+}
+
+// N returns the number of bits per codeword.
+func (b *BCH) N() int {
+	return b.n
+}
+
+// K returns the number of data bits per codeword.
+func (b *BCH) K() int {
+	return b.k
+}
+
+// T returns the number of correctable bit errors.
+func (b *BCH) T() int {
+	return b.t
+}
+
+// Generator returns the generator polynomial, highest degree first.
+func (b *BCH) Generator() *bitarray.BitArray {
+	out := bitarray.New()
+	for i := len(b.gen) - 1; i >= 0; i-- {
+		out.AddBit(b.gen[i])
+	}
+	return out
+}
+
+// Encode reads k bits from r and adds the codeword to ba.
+func (b *BCH) Encode(r *bitarray.Reader, ba *bitarray.BitArray) error {
+	np := b.n - b.k
+	p := make([]uint, np)
+	for i := 0; i < b.k; i++ {
+		var d uint
+		if err := r.ReadBits(&d, 1); err != nil {
+			return err
+		}
+		ba.AddBit(d)
+		fb := d ^ p[np-1]
+		for j := np - 1; j > 0; j-- {
+			p[j] = p[j-1] ^ fb&b.gen[j]
+		}
+		p[0] = fb & b.gen[0]
+	}
+	for j := np - 1; j >= 0; j-- {
+		ba.AddBit(p[j])
+	}
+	return nil
+}
+
+// Decode reads an n bit codeword from r and adds the corrected k data bits to
+// ba, returning the indexes of corrected bits within the codeword.
+func (b *BCH) Decode(r *bitarray.Reader, ba *bitarray.BitArray) ([]int, error) {
+	code, err := readSymbols(r, b.n, 1)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := b.syndromes(code)
+	var out []int
+	if !ok {
+		lambda, l := b.f.berlekampMassey(s)
+		if l > b.t {
+			return nil, fmt.Errorf("%w: %d errors", ErrUncorrectable, l)
+		}
+		degrees := b.f.chien(lambda, b.n)
+		if len(degrees) != l {
+			return nil, fmt.Errorf("%w: error locations outside codeword", ErrUncorrectable)
+		}
+		for _, d := range degrees {
+			code[b.n-1-d] ^= 1
+			out = append(out, b.n-1-d)
+		}
+		if _, ok := b.syndromes(code); !ok {
+			return nil, fmt.Errorf("%w: correction failed", ErrUncorrectable)
+		}
+		sort.Ints(out)
+	}
+	for _, c := range code[:b.k] {
+		ba.AddBit(c)
+	}
+	return out, nil
+}
+
+// Evaluate the codeword at alpha^1 to alpha^2t, returning true if all are
+// zero.
+func (b *BCH) syndromes(code []uint) ([]uint, bool) {
+	s := make([]uint, 2*b.t)
+	ok := true
+	for j := range s {
+		x := b.f.Exp(j + 1)
+		for _, c := range code {
+			s[j] = b.f.Mul(s[j], x) ^ c
+		}
+		if s[j] != 0 {
+			ok = false
+		}
+	}
+	return s, ok
+}
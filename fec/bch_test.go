@@ -0,0 +1,122 @@
+package fec
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestBCHGenerator(t *testing.T) {
+	f, _ := NewField(4, 0x13)
+	tests := map[string]struct {
+		t        int
+		k        int
+		expected string
+	}{
+		"bch(15,11)": {1, 11, "10011"},
+		"bch(15,7)":  {2, 7, "111010001"},
+		"bch(15,5)":  {3, 5, "10100110111"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := NewBCH(f, 15, tt.t)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if b.K() != tt.k {
+				t.Errorf("got k=%d, want %d", b.K(), tt.k)
+			}
+			expected, _ := bitarray.NewFromString(tt.expected)
+			if actual := b.Generator().String(); actual != expected.String() {
+				t.Errorf("got %s, want %s", actual, expected)
+			}
+		})
+	}
+}
+
+func TestBCH(t *testing.T) {
+	tests := map[string]struct {
+		m    int
+		poly uint
+		n, t int
+	}{
+		"bch(15,5)":    {4, 0x13, 15, 3},
+		"bch(63,45)":   {6, 0x43, 63, 3},
+		"bch(255,131)": {8, 0x11d, 255, 18},
+		"shortened":    {8, 0x11d, 100, 4},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			f, _ := NewField(tt.m, tt.poly)
+			b, err := NewBCH(f, tt.n, tt.t)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			data := bitarray.New()
+			for i := 0; i < b.K(); i++ {
+				data.AddBit(uint(rnd.Intn(2)))
+			}
+			code := bitarray.New()
+			code.AddN(0x3, 2)
+			if err := b.Encode(bitarray.NewReader(data), code); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if code.Len() != int64(2+tt.n) {
+				t.Fatalf("got len=%d, want %d", code.Len(), 2+tt.n)
+			}
+			if actual, _ := code.Slice(2, int64(b.K())); actual.String() != data.String() {
+				t.Errorf("got %s, want systematic %s", actual, data)
+			}
+
+			positions := rnd.Perm(tt.n)[:tt.t]
+			for _, p := range positions {
+				code.Flip(int64(2 + p))
+			}
+			r := bitarray.NewReader(code)
+			r.Seek(2, bitarray.SeekStart)
+			out := bitarray.New()
+			corrected, err := b.Decode(r, out)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if out.String() != data.String() {
+				t.Errorf("got %s, want %s", out, data)
+			}
+			if len(corrected) != tt.t {
+				t.Errorf("got corrected %v, want %v", corrected, positions)
+			}
+		})
+	}
+}
+
+func TestBCHUncorrectable(t *testing.T) {
+	f, _ := NewField(4, 0x13)
+	b, _ := NewBCH(f, 15, 2)
+	code := bitarray.New()
+	b.Encode(bitarray.NewReader(bitarray.New(bitarray.SetSize(7))), code)
+	// Three errors in the all zero codeword, beyond t=2 and not within two
+	// bits of another codeword
+	code.Set(0)
+	code.Set(1)
+	code.Set(5)
+	corrected, err := b.Decode(bitarray.NewReader(code), bitarray.New())
+	if !errors.Is(err, ErrUncorrectable) {
+		t.Errorf("got %v %v, want %v", corrected, err, ErrUncorrectable)
+	}
+
+	code.Unset(5)
+	corrected, err = b.Decode(bitarray.NewReader(code), bitarray.New())
+	if err != nil || !reflect.DeepEqual(corrected, []int{0, 1}) {
+		t.Errorf("got %v %v, want [0 1]", corrected, err)
+	}
+
+	if _, err := NewBCH(f, 15, 8); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+}
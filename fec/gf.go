@@ -0,0 +1,170 @@
+// Package fec implements Reed-Solomon and binary BCH forward error correction
+// codes over GF(2^m), reading and writing codewords at any bit offset.
+package fec
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUncorrectable is returned when a codeword has more errors than the
+	// code can correct.
+	ErrUncorrectable = errors.New("uncorrectable error")
+	// ErrParams is returned for invalid field or code parameters.
+	ErrParams = errors.New("invalid parameters")
+)
+
+// Field is GF(2^m) generated by a primitive polynomial. Elements are the
+// polynomial basis representation as a uint. Arguments wider than m bits are
+// reduced to their low m bits.
+type Field struct {
+	m   int
+	q   int
+	exp []uint
+	log []int
+}
+
+// NewField creates GF(2^m) from the primitive polynomial poly, including its
+// x^m term. For example, 0x11d for the GF(256) of QR codes.
+func NewField(m int, poly uint) (*Field, error) {
+	if m < 2 || m > 16 {
+		return nil, fmt.Errorf("%w: m=%d", ErrParams, m)
+	}
+	q := 1 << uint(m)
+	if poly>>uint(m) != 1 {
+		return nil, fmt.Errorf("%w: polynomial %#x is not of degree %d", ErrParams, poly, m)
+	}
+	f := &Field{m: m, q: q, exp: make([]uint, 2*(q-1)), log: make([]int, q)}
+	for i := range f.log {
+		f.log[i] = -1
+	}
+	x := uint(1)
+	for i := 0; i < q-1; i++ {
+		if f.log[x] >= 0 {
+			return nil, fmt.Errorf("%w: polynomial %#x is not primitive", ErrParams, poly)
+		}
+		f.exp[i] = x
+		f.exp[i+q-1] = x
+		f.log[x] = i
+		x <<= 1
+		if x&uint(q) != 0 {
+			x ^= poly
+		}
+	}
+	return f, nil
+}
+
+// M returns the number of bits per element.
+func (f *Field) M() int {
+	return f.m
+}
+
+// Mul returns a*b.
+func (f *Field) Mul(a, b uint) uint {
+	a, b = f.elem(a), f.elem(b)
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]+f.log[b]]
+}
+
+// Div returns a/b. It panics if b is zero.
+func (f *Field) Div(a, b uint) uint {
+	a, b = f.elem(a), f.elem(b)
+	if b == 0 {
+		panic("fec: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]-f.log[b]+f.q-1]
+}
+
+// Inv returns the multiplicative inverse of a.
+func (f *Field) Inv(a uint) uint {
+	return f.Div(1, a)
+}
+
+// Exp returns alpha^i, for any integer i.
+func (f *Field) Exp(i int) uint {
+	i %= f.q - 1
+	if i < 0 {
+		i += f.q - 1
+	}
+	return f.exp[i]
+}
+
+// Log returns i where alpha^i is a, or -1 for zero.
+func (f *Field) Log(a uint) int {
+	return f.log[f.elem(a)]
+}
+
+// Reduce a to the low m bits.
+func (f *Field) elem(a uint) uint {
+	return a & uint(f.q-1)
+}
+
+// Evaluate the polynomial p, lowest degree first, at x.
+func (f *Field) eval(p []uint, x uint) uint {
+	var y uint
+	for i := len(p) - 1; i >= 0; i-- {
+		y = f.Mul(y, x) ^ p[i]
+	}
+	return y
+}
+
+// Multiply polynomials, lowest degree first.
+func (f *Field) polyMul(a, b []uint) []uint {
+	out := make([]uint, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] ^= f.Mul(x, y)
+		}
+	}
+	return out
+}
+
+// Find the error locator polynomial, lowest degree first, for syndromes s
+// using Berlekamp-Massey. Returns the polynomial and its degree.
+func (f *Field) berlekampMassey(s []uint) ([]uint, int) {
+	c := make([]uint, len(s)+1)
+	b := make([]uint, len(s)+1)
+	c[0], b[0] = 1, 1
+	l, shift, lastD := 0, 1, uint(1)
+	for n := range s {
+		d := s[n]
+		for i := 1; i <= l; i++ {
+			d ^= f.Mul(c[i], s[n-i])
+		}
+		if d == 0 {
+			shift++
+			continue
+		}
+		coef := f.Div(d, lastD)
+		t := append([]uint(nil), c...)
+		for i := 0; i+shift < len(c); i++ {
+			c[i+shift] ^= f.Mul(coef, b[i])
+		}
+		if 2*l <= n {
+			l = n + 1 - l
+			b = t
+			lastD = d
+			shift = 1
+		} else {
+			shift++
+		}
+	}
+	return c[:l+1], l
+}
+
+// Find the degrees d < n where lambda(alpha^-d) is zero using Chien search.
+func (f *Field) chien(lambda []uint, n int) []int {
+	var out []int
+	for d := 0; d < n; d++ {
+		if f.eval(lambda, f.Exp(-d)) == 0 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
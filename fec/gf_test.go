@@ -0,0 +1,62 @@
+package fec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewField(t *testing.T) {
+	tests := map[string]struct {
+		m    int
+		poly uint
+		err  error
+	}{
+		"gf16":         {4, 0x13, nil},
+		"gf256":        {8, 0x11d, nil},
+		"gf1024":       {10, 0x409, nil},
+		"notPrimitive": {8, 0x11b, ErrParams},
+		"degree":       {8, 0x1d, ErrParams},
+		"small":        {1, 0x3, ErrParams},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewField(tt.m, tt.poly); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	f, err := NewField(8, 0x11d)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := f.Exp(8); actual != 0x1d {
+		t.Errorf("got alpha^8=%#x, want 0x1d", actual)
+	}
+	if actual := f.Exp(-1); actual != f.Exp(254) {
+		t.Errorf("got alpha^-1=%#x, want %#x", actual, f.Exp(254))
+	}
+	if f.Log(0) != -1 || f.Log(1) != 0 || f.Log(2) != 1 {
+		t.Errorf("got logs %d %d %d", f.Log(0), f.Log(1), f.Log(2))
+	}
+	for a := uint(1); a < 256; a++ {
+		if actual := f.Mul(a, f.Inv(a)); actual != 1 {
+			t.Fatalf("got %#x * inverse = %#x", a, actual)
+		}
+		for b := uint(1); b < 256; b += 7 {
+			if actual := f.Div(f.Mul(a, b), b); actual != a {
+				t.Fatalf("got %#x*%#x/%#x = %#x", a, b, b, actual)
+			}
+		}
+	}
+	if f.Mul(0, 5) != 0 || f.Div(0, 5) != 0 {
+		t.Error("expected zero")
+	}
+	// Wide arguments are reduced to 8 bits
+	if f.Mul(0x102, 3) != f.Mul(2, 3) || f.Log(0x100) != -1 || f.Div(0x305, 0x101) != 5 {
+		t.Error("expected arguments reduced to 8 bits")
+	}
+}
@@ -0,0 +1,174 @@
+package fec
+
+import (
+	"fmt"
+	"sort"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// ReedSolomon is a systematic Reed-Solomon code of n symbols with k data
+// symbols, correcting up to (n-k)/2 symbol errors. Codes shorter than 2^m-1
+// are shortened. The first symbol of a codeword is the highest degree.
+type ReedSolomon struct {
+	f    *Field
+	n, k int
+	fcr  int
+	// Generator polynomial, lowest degree first
+	gen []uint
+}
+
+// NewReedSolomon creates a code over f whose generator polynomial has the
+// roots alpha^fcr to alpha^(fcr+n-k-1).
+func NewReedSolomon(f *Field, n, k, fcr int) (*ReedSolomon, error) {
+	if n > f.q-1 || k < 1 || k >= n {
+		return nil, fmt.Errorf("%w: RS(%d,%d) over GF(2^%d)", ErrParams, n, k, f.m)
+	}
+	gen := []uint{1}
+	for i := 0; i < n-k; i++ {
+		gen = f.polyMul(gen, []uint{f.Exp(fcr + i), 1})
+	}
+	return &ReedSolomon{f: f, n: n, k: k, fcr: fcr, gen: gen}, nil
+}
+
+// N returns the number of symbols per codeword.
+func (rs *ReedSolomon) N() int {
+	return rs.n
+}
+
+// K returns the number of data symbols per codeword.
+func (rs *ReedSolomon) K() int {
+	return rs.k
+}
+
+// EncodeSymbols returns the codeword for k data symbols, the data followed by
+// n-k parity symbols.
+func (rs *ReedSolomon) EncodeSymbols(data []uint) ([]uint, error) {
+	if len(data) != rs.k {
+		return nil, fmt.Errorf("%w: %d data symbols, want %d", ErrParams, len(data), rs.k)
+	}
+	np := rs.n - rs.k
+	p := make([]uint, np)
+	for _, d := range data {
+		if d >= uint(rs.f.q) {
+			return nil, fmt.Errorf("%w: symbol %#x exceeds %d bits", bitarray.ErrOverflow, d, rs.f.m)
+		}
+		fb := d ^ p[np-1]
+		for j := np - 1; j > 0; j-- {
+			p[j] = p[j-1] ^ rs.f.Mul(fb, rs.gen[j])
+		}
+		p[0] = rs.f.Mul(fb, rs.gen[0])
+	}
+	out := append(make([]uint, 0, rs.n), data...)
+	for j := np - 1; j >= 0; j-- {
+		out = append(out, p[j])
+	}
+	return out, nil
+}
+
+// DecodeSymbols corrects the n symbol codeword in place and returns the
+// indexes of corrected symbols.
+func (rs *ReedSolomon) DecodeSymbols(code []uint) ([]int, error) {
+	if len(code) != rs.n {
+		return nil, fmt.Errorf("%w: %d symbols, want %d", ErrParams, len(code), rs.n)
+	}
+	for _, c := range code {
+		if c >= uint(rs.f.q) {
+			return nil, fmt.Errorf("%w: symbol %#x exceeds %d bits", bitarray.ErrOverflow, c, rs.f.m)
+		}
+	}
+	s, ok := rs.syndromes(code)
+	if ok {
+		return nil, nil
+	}
+	lambda, l := rs.f.berlekampMassey(s)
+	if l > (rs.n-rs.k)/2 {
+		return nil, fmt.Errorf("%w: %d errors", ErrUncorrectable, l)
+	}
+	degrees := rs.f.chien(lambda, rs.n)
+	if len(degrees) != l {
+		return nil, fmt.Errorf("%w: error locations outside codeword", ErrUncorrectable)
+	}
+	// Forney, omega(x) = s(x)lambda(x) mod x^(n-k)
+	omega := rs.f.polyMul(s, lambda)[:len(s)]
+	deriv := make([]uint, len(lambda))
+	for i := 1; i < len(lambda); i += 2 {
+		deriv[i-1] = lambda[i]
+	}
+	fixed := make([]uint, rs.n)
+	copy(fixed, code)
+	var out []int
+	for _, d := range degrees {
+		xInv := rs.f.Exp(-d)
+		e := rs.f.Div(rs.f.eval(omega, xInv), rs.f.eval(deriv, xInv))
+		e = rs.f.Mul(e, rs.f.Exp(d*(1-rs.fcr)))
+		fixed[rs.n-1-d] ^= e
+		out = append(out, rs.n-1-d)
+	}
+	if _, ok := rs.syndromes(fixed); !ok {
+		return nil, fmt.Errorf("%w: correction failed", ErrUncorrectable)
+	}
+	copy(code, fixed)
+	sort.Ints(out)
+	return out, nil
+}
+
+// Encode reads k symbols of m bits from r and adds the codeword to ba.
+func (rs *ReedSolomon) Encode(r *bitarray.Reader, ba *bitarray.BitArray) error {
+	data, err := readSymbols(r, rs.k, rs.f.m)
+	if err != nil {
+		return err
+	}
+	code, err := rs.EncodeSymbols(data)
+	if err != nil {
+		return err
+	}
+	for _, c := range code {
+		ba.AddN(c, rs.f.m)
+	}
+	return nil
+}
+
+// Decode reads an n symbol codeword from r and adds the corrected k data
+// symbols to ba, returning the indexes of corrected symbols.
+func (rs *ReedSolomon) Decode(r *bitarray.Reader, ba *bitarray.BitArray) ([]int, error) {
+	code, err := readSymbols(r, rs.n, rs.f.m)
+	if err != nil {
+		return nil, err
+	}
+	corrected, err := rs.DecodeSymbols(code)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range code[:rs.k] {
+		ba.AddN(c, rs.f.m)
+	}
+	return corrected, nil
+}
+
+// Evaluate the codeword at alpha^(fcr+j) for each parity symbol j, returning
+// true if all are zero.
+func (rs *ReedSolomon) syndromes(code []uint) ([]uint, bool) {
+	s := make([]uint, rs.n-rs.k)
+	ok := true
+	for j := range s {
+		x := rs.f.Exp(rs.fcr + j)
+		for _, c := range code {
+			s[j] = rs.f.Mul(s[j], x) ^ c
+		}
+		if s[j] != 0 {
+			ok = false
+		}
+	}
+	return s, ok
+}
+
+func readSymbols(r *bitarray.Reader, n, width int) ([]uint, error) {
+	out := make([]uint, n)
+	for i := range out {
+		if err := r.ReadBits(&out[i], width); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,150 @@
+package fec
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestReedSolomonQR(t *testing.T) {
+	// QR code version 1-M "HELLO WORLD"
+	f, _ := NewField(8, 0x11d)
+	rs, err := NewReedSolomon(f, 26, 16, 0)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	data := []uint{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	code, err := rs.EncodeSymbols(data)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	expected := []uint{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if !reflect.DeepEqual(code[16:], expected) {
+		t.Errorf("got %v, want %v", code[16:], expected)
+	}
+	if !reflect.DeepEqual(code[:16], data) {
+		t.Errorf("got data %v, want %v", code[:16], data)
+	}
+
+	code[0] ^= 0xff
+	code[10] ^= 0x01
+	code[17] = 0
+	code[25] ^= 0x80
+	corrected, err := rs.DecodeSymbols(code)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if !reflect.DeepEqual(corrected, []int{0, 10, 17, 25}) {
+		t.Errorf("got corrected %v", corrected)
+	}
+	if !reflect.DeepEqual(code[:16], data) || !reflect.DeepEqual(code[16:], expected) {
+		t.Errorf("got %v", code)
+	}
+}
+
+func TestReedSolomonCodes(t *testing.T) {
+	tests := map[string]struct {
+		m       int
+		poly    uint
+		n, k    int
+		fcr     int
+		badBits bool
+	}{
+		"rs(15,9)":       {4, 0x13, 15, 9, 1, false},
+		"rs(255,223)":    {8, 0x187, 255, 223, 112, false},
+		"shortened":      {8, 0x11d, 40, 30, 0, false},
+		"gf1024":         {10, 0x409, 100, 80, 1, false},
+		"gf16Shortened":  {4, 0x13, 10, 6, 0, false},
+		"gf1024TooMany":  {10, 0x409, 60, 50, 1, true},
+		"gf256TooMany":   {8, 0x11d, 30, 20, 0, true},
+		"gf16Narrowband": {4, 0x19, 15, 11, 1, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			f, err := NewField(tt.m, tt.poly)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			rs, err := NewReedSolomon(f, tt.n, tt.k, tt.fcr)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			// Data at an odd bit offset
+			data := bitarray.New()
+			data.AddN(0x5, 3)
+			for i := 0; i < tt.k; i++ {
+				data.AddN(uint(rnd.Intn(1<<uint(tt.m))), tt.m)
+			}
+			r := bitarray.NewReader(data)
+			r.Seek(3, bitarray.SeekStart)
+			code := bitarray.New()
+			code.AddN(0x1, 5)
+			if err := rs.Encode(r, code); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if code.Len() != int64(5+tt.n*tt.m) {
+				t.Fatalf("got len=%d, want %d", code.Len(), 5+tt.n*tt.m)
+			}
+
+			// Corrupt up to t symbols, or one more when uncorrectable
+			nerr := (tt.n - tt.k) / 2
+			if tt.badBits {
+				nerr++
+			}
+			var positions []int
+			for _, p := range rnd.Perm(tt.n)[:nerr] {
+				positions = append(positions, p)
+				i := int64(5 + p*tt.m + rnd.Intn(tt.m))
+				code.Flip(i)
+			}
+
+			r = bitarray.NewReader(code)
+			r.Seek(5, bitarray.SeekStart)
+			out := bitarray.New()
+			corrected, err := rs.Decode(r, out)
+			if tt.badBits {
+				if !errors.Is(err, ErrUncorrectable) {
+					t.Errorf("got %v, want %v", err, ErrUncorrectable)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if len(corrected) != nerr {
+				t.Errorf("got %d corrected, want %d", len(corrected), nerr)
+			}
+			expected, _ := data.Slice(3, data.Len()-3)
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+		})
+	}
+}
+
+func TestReedSolomonErrors(t *testing.T) {
+	f, _ := NewField(4, 0x13)
+	if _, err := NewReedSolomon(f, 16, 8, 0); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+	rs, _ := NewReedSolomon(f, 15, 11, 0)
+	if _, err := rs.EncodeSymbols(make([]uint, 10)); !errors.Is(err, ErrParams) {
+		t.Errorf("got %v, want %v", err, ErrParams)
+	}
+	if _, err := rs.EncodeSymbols(append(make([]uint, 10), 16)); !errors.Is(err, bitarray.ErrOverflow) {
+		t.Errorf("got %v, want %v", err, bitarray.ErrOverflow)
+	}
+	code, _ := rs.EncodeSymbols(make([]uint, 11))
+	code[3] = 0x20
+	if _, err := rs.DecodeSymbols(code); !errors.Is(err, bitarray.ErrOverflow) {
+		t.Errorf("got %v, want %v", err, bitarray.ErrOverflow)
+	}
+	if err := rs.Encode(bitarray.NewReader(bitarray.New()), bitarray.New()); !errors.Is(err, bitarray.EOF) {
+		t.Errorf("got %v, want %v", err, bitarray.EOF)
+	}
+}
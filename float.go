@@ -0,0 +1,143 @@
+package bitarray
+
+import (
+	"fmt"
+	"math"
+)
+
+// FloatFormat describes an IEEE 754 style binary floating point format with a
+// sign bit, ExpBits of biased exponent and MantBits of stored mantissa.
+type FloatFormat struct {
+	ExpBits  int
+	MantBits int
+	Bias     int
+}
+
+var (
+	// Binary16 is the IEEE 754 half precision format.
+	Binary16 = FloatFormat{ExpBits: 5, MantBits: 10, Bias: 15}
+	// BFloat16 is the brain floating point format.
+	BFloat16 = FloatFormat{ExpBits: 8, MantBits: 7, Bias: 127}
+	// Binary32 is the IEEE 754 single precision format.
+	Binary32 = FloatFormat{ExpBits: 8, MantBits: 23, Bias: 127}
+	// Binary64 is the IEEE 754 double precision format.
+	Binary64 = FloatFormat{ExpBits: 11, MantBits: 52, Bias: 1023}
+)
+
+// Width returns the number of bits in the format.
+func (ff FloatFormat) Width() int {
+	return 1 + ff.ExpBits + ff.MantBits
+}
+
+func (ff FloatFormat) validate() error {
+	if ff.ExpBits < 1 || ff.ExpBits > 11 {
+		return fmt.Errorf("invalid float format: exponent bits %d not in 1..11", ff.ExpBits)
+	}
+	if ff.MantBits < 1 || ff.MantBits > 52 {
+		return fmt.Errorf("invalid float format: mantissa bits %d not in 1..52", ff.MantBits)
+	}
+	return nil
+}
+
+// AddFloat adds f encoded in format, rounding to nearest even.
+// Returns the number of bits added.
+func (ba *BitArray) AddFloat(f float64, format FloatFormat) (int, error) {
+	if err := format.validate(); err != nil {
+		return 0, err
+	}
+	return ba.AddN(uint(format.encode(f)), format.Width()), nil
+}
+
+// ReadFloat reads a float encoded in format.
+func (r *Reader) ReadFloat(format FloatFormat) (float64, error) {
+	if err := format.validate(); err != nil {
+		return 0, err
+	}
+	var u uint
+	if err := r.ReadBits(&u, format.Width()); err != nil {
+		return 0, err
+	}
+	return format.decode(uint64(u)), nil
+}
+
+func (ff FloatFormat) encode(x float64) uint64 {
+	mantBits := uint(ff.MantBits)
+	maxExp := uint64(1)<<uint(ff.ExpBits) - 1
+	var sign uint64
+	if math.Signbit(x) {
+		sign = 1 << uint(ff.ExpBits+ff.MantBits)
+	}
+	switch {
+	case math.IsNaN(x):
+		return sign | maxExp<<mantBits | 1<<(mantBits-1)
+	case math.IsInf(x, 0):
+		return sign | maxExp<<mantBits
+	case x == 0:
+		return sign
+	}
+
+	// x = frac * 2^exp with frac in [0.5, 1), held exactly as a 53 bit integer
+	frac, exp := math.Frexp(math.Abs(x))
+	sig := uint64(math.Ldexp(frac, 53))
+	e := int64(exp-1) + int64(ff.Bias)
+	shift := int64(52 - ff.MantBits)
+	if e < 1 {
+		// Subnormal, scale down to the minimum exponent
+		shift += 1 - e
+		e = 0
+	}
+	m := roundShift(sig, shift)
+	if e == 0 {
+		if m>>mantBits != 0 {
+			// Rounded up to the smallest normal
+			e = 1
+		}
+	} else if m>>(mantBits+1) != 0 {
+		m >>= 1
+		e++
+	}
+	if e >= int64(maxExp) {
+		return sign | maxExp<<mantBits
+	}
+	m &= 1<<mantBits - 1
+	return sign | uint64(e)<<mantBits | m
+}
+
+// Shift x right by s bits, rounding to nearest even.
+func roundShift(x uint64, s int64) uint64 {
+	if s <= 0 {
+		return x
+	}
+	if s >= 64 {
+		return 0
+	}
+	q := x >> uint(s)
+	r := x & (1<<uint(s) - 1)
+	half := uint64(1) << uint(s-1)
+	if r > half || (r == half && q&1 == 1) {
+		q++
+	}
+	return q
+}
+
+func (ff FloatFormat) decode(u uint64) float64 {
+	mantBits := uint(ff.MantBits)
+	maxExp := uint64(1)<<uint(ff.ExpBits) - 1
+	m := u & (1<<mantBits - 1)
+	e := (u >> mantBits) & maxExp
+	var x float64
+	switch {
+	case e == maxExp && m == 0:
+		x = math.Inf(1)
+	case e == maxExp:
+		x = math.NaN()
+	case e == 0:
+		x = math.Ldexp(float64(m), 1-ff.Bias-ff.MantBits)
+	default:
+		x = math.Ldexp(float64(m|1<<mantBits), int(e)-ff.Bias-ff.MantBits)
+	}
+	if u>>uint(ff.ExpBits+ff.MantBits)&1 == 1 {
+		x = math.Copysign(x, -1)
+	}
+	return x
+}
@@ -0,0 +1,125 @@
+package bitarray
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAddFloat(t *testing.T) {
+	tests := map[string]struct {
+		in       float64
+		format   FloatFormat
+		expected uint
+	}{
+		"halfOne":           {1, Binary16, 0x3c00},
+		"halfMinusTwo":      {-2, Binary16, 0xc000},
+		"halfThird":         {1.0 / 3, Binary16, 0x3555},
+		"halfTenth":         {0.1, Binary16, 0x2e66},
+		"halfMax":           {65504, Binary16, 0x7bff},
+		"halfOverflow":      {65520, Binary16, 0x7c00},
+		"halfNegInf":        {math.Inf(-1), Binary16, 0xfc00},
+		"halfNaN":           {math.NaN(), Binary16, 0x7e00},
+		"halfNegZero":       {math.Copysign(0, -1), Binary16, 0x8000},
+		"halfSubnormal":     {math.Ldexp(1, -24), Binary16, 0x0001},
+		"halfTiesToZero":    {math.Ldexp(1, -25), Binary16, 0x0000},
+		"halfRoundUp":       {math.Ldexp(3, -26), Binary16, 0x0001},
+		"halfToNormal":      {math.Ldexp(1023.5, -24), Binary16, 0x0400},
+		"halfTiesToEven":    {1 + math.Ldexp(1, -11), Binary16, 0x3c00},
+		"halfTiesToEvenUp":  {1 + math.Ldexp(3, -11), Binary16, 0x3c02},
+		"bfloatOne":         {1, BFloat16, 0x3f80},
+		"bfloatPi":          {math.Pi, BFloat16, 0x4049},
+		"minifloat":         {1, FloatFormat{ExpBits: 4, MantBits: 3, Bias: 7}, 0x38},
+		"minifloatNegHalf":  {-0.5, FloatFormat{ExpBits: 4, MantBits: 3, Bias: 7}, 0xb0},
+		"minifloatOverflow": {1000, FloatFormat{ExpBits: 4, MantBits: 3, Bias: 7}, 0x78},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			n, err := ba.AddFloat(tt.in, tt.format)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if n != tt.format.Width() {
+				t.Errorf("got n=%d, want %d", n, tt.format.Width())
+			}
+			actual, _ := ba.ReadUint(0, int64(n))
+			if actual != tt.expected {
+				t.Errorf("got %#x, want %#x", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddFloatMatchesMath(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		x := math.Float64frombits(rnd.Uint64())
+		if i%2 == 0 {
+			// Bias towards the float32 range, including subnormals
+			x = math.Ldexp(rnd.Float64(), rnd.Intn(300)-160)
+		}
+		ba := New()
+		ba.AddFloat(x, Binary32)
+		ba.AddFloat(x, Binary64)
+		f32, _ := ba.ReadUint(0, 32)
+		f64, _ := ba.ReadUint(32, 64)
+		if math.IsNaN(x) {
+			continue
+		}
+		if want := math.Float32bits(float32(x)); uint32(f32) != want {
+			t.Fatalf("%g: got %#x, want %#x", x, f32, want)
+		}
+		if want := math.Float64bits(x); uint64(f64) != want {
+			t.Fatalf("%g: got %#x, want %#x", x, f64, want)
+		}
+	}
+}
+
+func TestReadFloat(t *testing.T) {
+	tests := map[string]struct {
+		in     float64
+		format FloatFormat
+	}{
+		"half":          {-1.5, Binary16},
+		"halfSubnormal": {math.Ldexp(5, -24), Binary16},
+		"halfInf":       {math.Inf(1), Binary16},
+		"bfloat":        {256, BFloat16},
+		"single":        {math.Ldexp(3, -140), Binary32},
+		"double":        {math.SmallestNonzeroFloat64, Binary64},
+		"minifloat":     {-0.0078125, FloatFormat{ExpBits: 4, MantBits: 3, Bias: 7}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			ba.AddBit(1)
+			if _, err := ba.AddFloat(tt.in, tt.format); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			r := NewReader(ba)
+			r.ReadBit()
+			actual, err := r.ReadFloat(tt.format)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual != tt.in {
+				t.Errorf("got %g, want %g", actual, tt.in)
+			}
+		})
+	}
+
+	r := NewReader(NewFromBytes([]byte{0xfe, 0x00}, 16))
+	if actual, _ := r.ReadFloat(Binary16); !math.IsNaN(actual) {
+		t.Errorf("got %g, want NaN", actual)
+	}
+}
+
+func TestFloatFormatInvalid(t *testing.T) {
+	for _, ff := range []FloatFormat{{}, {ExpBits: 12, MantBits: 3}, {ExpBits: 4, MantBits: 53}} {
+		if _, err := New().AddFloat(1, ff); err == nil {
+			t.Errorf("%+v: expected error", ff)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package bitarray
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+)
+
+// Format is a compiled bitstruct style format string.
+//
+// A format is a sequence of fields, each a type character followed by its
+// width in bits:
+//
+//	u  unsigned integer
+//	s  signed (two's complement) integer
+//	f  IEEE 754 float, 16, 32 or 64 bits wide
+//	b  boolean
+//	t  text, a string of 8 bit bytes
+//	r  raw bits from a []byte
+//	p  padding with zeros, takes no value
+//	P  padding with ones, takes no value
+//
+// Numeric and boolean fields are big-endian by default. A '<' prefix switches
+// the following fields to little-endian byte order and '>' switches back.
+// Little-endian fields wider than 8 bits must be a whole number of bytes.
+type Format struct {
+	fields []formatField
+	size   int
+}
+
+type formatField struct {
+	kind         byte
+	width        int
+	littleEndian bool
+}
+
+var formatCache sync.Map
+
+// CompileFormat parses a format string. Compiled formats are cached.
+func CompileFormat(format string) (*Format, error) {
+	if f, ok := formatCache.Load(format); ok {
+		return f.(*Format), nil
+	}
+	f, err := parseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	formatCache.Store(format, f)
+	return f, nil
+}
+
+func parseFormat(format string) (*Format, error) {
+	out := &Format{}
+	littleEndian := false
+	for i := 0; i < len(format); {
+		c := format[i]
+		switch c {
+		case ' ':
+			i++
+			continue
+		case '>':
+			littleEndian = false
+			i++
+			continue
+		case '<':
+			littleEndian = true
+			i++
+			continue
+		case 'u', 's', 'f', 'b', 't', 'r', 'p', 'P':
+		default:
+			return nil, fmt.Errorf("bad format %q: unknown type %q at %d", format, c, i)
+		}
+		start := i
+		i++
+		width := 0
+		for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			width = width*10 + int(format[i]-'0')
+			if width > math.MaxInt32 {
+				return nil, fmt.Errorf("bad format %q: width too large at %d", format, start)
+			}
+		}
+		if width == 0 {
+			return nil, fmt.Errorf("bad format %q: missing width at %d", format, start)
+		}
+		switch c {
+		case 'u', 's', 'b':
+			if width > 64 {
+				return nil, fmt.Errorf("bad format %q: integer wider than 64 bits at %d", format, start)
+			}
+		case 'f':
+			if !validFloatWidth(width) {
+				return nil, fmt.Errorf("bad format %q: invalid float width at %d", format, start)
+			}
+		case 't':
+			if width%8 != 0 {
+				return nil, fmt.Errorf("bad format %q: text width not a multiple of 8 at %d", format, start)
+			}
+		}
+		if littleEndian && width > 8 && width%8 != 0 {
+			switch c {
+			case 'u', 's', 'f', 'b':
+				return nil, fmt.Errorf("bad format %q: little-endian width not a multiple of 8 at %d", format, start)
+			}
+		}
+		out.fields = append(out.fields, formatField{kind: c, width: width, littleEndian: littleEndian})
+		out.size += width
+	}
+	return out, nil
+}
+
+func validFloatWidth(width int) bool {
+	return width == 16 || width == 32 || width == 64
+}
+
+// Len returns the number of bits described by the format.
+func (f *Format) Len() int {
+	return f.size
+}
+
+// Pack packs values according to the format into a new BitArray.
+func (f *Format) Pack(values ...interface{}) (*BitArray, error) {
+	out := New()
+	if err := f.PackInto(out, values...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackInto packs values according to the format on the end of ba.
+func (f *Format) PackInto(ba *BitArray, values ...interface{}) error {
+	vi := 0
+	for _, fld := range f.fields {
+		if fld.kind == 'p' || fld.kind == 'P' {
+			var u uint
+			if fld.kind == 'P' {
+				u = maxUint(fld.width)
+			}
+			padN(ba, u, fld.width)
+			continue
+		}
+		if vi >= len(values) {
+			return fmt.Errorf("format needs more than %d values", len(values))
+		}
+		if err := fld.pack(ba, values[vi]); err != nil {
+			return fmt.Errorf("value %d: %w", vi, err)
+		}
+		vi++
+	}
+	if vi != len(values) {
+		return fmt.Errorf("format takes %d values, got %d", vi, len(values))
+	}
+	return nil
+}
+
+// Add width bits of u, which may be wider than a uint.
+func padN(ba *BitArray, u uint, width int) {
+	for ; width > bits.UintSize; width -= bits.UintSize {
+		ba.AddN(u, bits.UintSize)
+	}
+	ba.AddN(u, width)
+}
+
+func (fld formatField) pack(ba *BitArray, v interface{}) error {
+	var u uint64
+	switch fld.kind {
+	case 'u':
+		i, signed, ok := formatInt(v)
+		if !ok {
+			return fmt.Errorf("cannot pack %T as unsigned", v)
+		}
+		if signed && int64(i) < 0 {
+			return fmt.Errorf("%w: negative value %d", ErrOverflow, int64(i))
+		}
+		if bits.Len64(i) > fld.width {
+			return fmt.Errorf("%w: %d needs %d bits, have %d", ErrOverflow, i, bits.Len64(i), fld.width)
+		}
+		u = i
+	case 's':
+		i, signed, ok := formatInt(v)
+		if !ok {
+			return fmt.Errorf("cannot pack %T as signed", v)
+		}
+		if !signed && i > math.MaxInt64 {
+			return fmt.Errorf("%w: %d needs %d bits, have %d", ErrOverflow, i, 65, fld.width)
+		}
+		s := int64(i)
+		if fld.width < 64 {
+			limit := int64(1) << uint(fld.width-1)
+			if s < -limit || s >= limit {
+				return fmt.Errorf("%w: %d does not fit %d signed bits", ErrOverflow, s, fld.width)
+			}
+		}
+		u = uint64(s) & uint64(maxUint(fld.width))
+	case 'f':
+		var x float64
+		switch c := v.(type) {
+		case float32:
+			x = float64(c)
+		case float64:
+			x = c
+		default:
+			return fmt.Errorf("cannot pack %T as float", v)
+		}
+		u = floatBits(x, fld.width)
+	case 'b':
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("cannot pack %T as bool", v)
+		}
+		if b {
+			u = 1
+		}
+	case 't':
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("cannot pack %T as text", v)
+		}
+		return packRaw(ba, []byte(s), fld.width)
+	case 'r':
+		b, ok := v.([]byte)
+		if !ok {
+			return fmt.Errorf("cannot pack %T as raw", v)
+		}
+		return packRaw(ba, b, fld.width)
+	}
+	u = fld.byteOrder(u)
+	ba.AddN(uint(u), fld.width)
+	return nil
+}
+
+// Swap the bytes of a little-endian field, in either direction.
+func (fld formatField) byteOrder(u uint64) uint64 {
+	if !fld.littleEndian || fld.width <= 8 {
+		return u
+	}
+	return bits.ReverseBytes64(u) >> uint(64-fld.width)
+}
+
+// Pack the leading width bits of b, padding with zeros if b is short.
+func packRaw(ba *BitArray, b []byte, width int) error {
+	if len(b)*8 > width+7 {
+		return fmt.Errorf("%d bytes do not fit %d bits", len(b), width)
+	}
+	full := width / 8
+	if full > len(b) {
+		full = len(b)
+	}
+	if err := ba.Pack(b[:full]); err != nil {
+		return err
+	}
+	width -= full * 8
+	if full < len(b) {
+		ba.AddN(uint(b[full]>>uint(8-width)), width)
+		return nil
+	}
+	padN(ba, 0, width)
+	return nil
+}
+
+// Unpack reads values according to the format from the start of ba.
+func (f *Format) Unpack(ba *BitArray) ([]interface{}, error) {
+	return f.Read(NewReader(ba))
+}
+
+// Read reads values according to the format from r.
+// Unsigned fields are returned as uint64, signed as int64, floats up to 32
+// bits wide as float32 and float64 otherwise, text as string and raw bits as
+// []byte.
+func (f *Format) Read(r *Reader) ([]interface{}, error) {
+	if avail := r.remaining(); avail < int64(f.size) {
+		return nil, fmt.Errorf("format needs %d bits, %d available", f.size, avail)
+	}
+	var out []interface{}
+	for _, fld := range f.fields {
+		switch fld.kind {
+		case 'p', 'P':
+			if _, err := r.Seek(int64(fld.width), SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		case 't', 'r':
+			b := make([]byte, (fld.width+7)/8)
+			for i := 0; i < fld.width; i += 8 {
+				n := 8
+				if fld.width-i < 8 {
+					n = fld.width - i
+				}
+				var c uint
+				if err := r.ReadBits(&c, n); err != nil {
+					return nil, err
+				}
+				b[i/8] = byte(c << uint(8-n))
+			}
+			if fld.kind == 't' {
+				out = append(out, string(b))
+			} else {
+				out = append(out, b)
+			}
+			continue
+		}
+		var c uint
+		if err := r.ReadBits(&c, fld.width); err != nil {
+			return nil, err
+		}
+		u := uint64(c)
+		u = fld.byteOrder(u)
+		switch fld.kind {
+		case 'u':
+			out = append(out, u)
+		case 's':
+			shift := uint(64 - fld.width)
+			out = append(out, int64(u<<shift)>>shift)
+		case 'f':
+			out = append(out, floatFromBits(u, fld.width))
+		case 'b':
+			out = append(out, u != 0)
+		}
+	}
+	return out, nil
+}
+
+// PackFormat packs values into a new BitArray according to format.
+func PackFormat(format string, values ...interface{}) (*BitArray, error) {
+	f, err := CompileFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return f.Pack(values...)
+}
+
+// UnpackFormat unpacks values from ba according to format.
+func UnpackFormat(format string, ba *BitArray) ([]interface{}, error) {
+	f, err := CompileFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return f.Unpack(ba)
+}
+
+// Return an integer value as 64 bits and whether it is signed.
+func formatInt(v interface{}) (uint64, bool, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(rv.Int()), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), false, true
+	}
+	return 0, false, false
+}
+
+func floatBits(x float64, width int) uint64 {
+	if width == 16 {
+		return Binary16.encode(x)
+	}
+	if width == 32 {
+		return uint64(math.Float32bits(float32(x)))
+	}
+	return math.Float64bits(x)
+}
+
+func floatFromBits(u uint64, width int) interface{} {
+	if width == 16 {
+		return float32(Binary16.decode(u))
+	}
+	if width == 32 {
+		return math.Float32frombits(uint32(u))
+	}
+	return math.Float64frombits(u)
+}
@@ -0,0 +1,137 @@
+package bitarray
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPackFormat(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		in       []interface{}
+		expected string
+	}{
+		"unsigned":     {"u1u3u4", []interface{}{1, uint8(2), 3}, "[10100011]"},
+		"signed":       {"s4s4", []interface{}{-1, int8(-8)}, "[11111000]"},
+		"padding":      {"u2p3P3", []interface{}{3}, "[11000111]"},
+		"bool":         {"b1b1b2", []interface{}{true, false, true}, "[1001----]"},
+		"littleEndian": {"<u16>u16", []interface{}{1, 1}, "[00000001 00000000 00000000 00000001]"},
+		"littleSmall":  {"<u4u4", []interface{}{1, 2}, "[00010010]"},
+		"littleSigned": {"<s24", []interface{}{-2}, "[11111110 11111111 11111111]"},
+		"text":         {"t16", []interface{}{"A"}, "[01000001 00000000]"},
+		"raw":          {"r12", []interface{}{[]byte{0xab, 0xcd}}, "[10101011 1100----]"},
+		"rawShort":     {"r12", []interface{}{[]byte{0xab}}, "[10101011 0000----]"},
+		"float32":      {"f32", []interface{}{float32(1)}, "[00111111 10000000 00000000 00000000]"},
+		"mixed":        {"u1u3s4p2b1r16", []interface{}{1, 2, -2, true, []byte{0xff, 0x00}}, "[10101110 00111111 11100000 000-----]"},
+		"wideUint":     {"u64", []interface{}{uint64(1) << 63}, "[10000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000]"},
+		"whitespace":   {"u4 u4", []interface{}{1, 2}, "[00010010]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba, err := PackFormat(tt.format, tt.in...)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			actual := ba.String()
+			if actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackFormatErrors(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		in     []interface{}
+		err    error
+	}{
+		"unknownType":   {"x4", nil, nil},
+		"missingWidth":  {"u", nil, nil},
+		"wideInteger":   {"u65", nil, nil},
+		"wideBool":      {"<b70", nil, nil},
+		"littleWidth":   {"<u12", nil, nil},
+		"floatWidth":    {"f24", nil, nil},
+		"textWidth":     {"t12", nil, nil},
+		"tooFewValues":  {"u1u1", []interface{}{1}, nil},
+		"tooManyValues": {"u1", []interface{}{1, 1}, nil},
+		"wrongType":     {"b1", []interface{}{1}, nil},
+		"unsigned":      {"u3", []interface{}{8}, ErrOverflow},
+		"negative":      {"u3", []interface{}{-1}, ErrOverflow},
+		"signed":        {"s3", []interface{}{4}, ErrOverflow},
+		"signedLow":     {"s3", []interface{}{-5}, ErrOverflow},
+		"rawLong":       {"r8", []interface{}{[]byte{1, 2}}, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := PackFormat(tt.format, tt.in...)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnpackFormat(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		in       []interface{}
+		expected []interface{}
+	}{
+		"mixed": {
+			"u1u3s4p2b1r16",
+			[]interface{}{1, 2, -2, true, []byte{0xff, 0x00}},
+			[]interface{}{uint64(1), uint64(2), int64(-2), true, []byte{0xff, 0x00}},
+		},
+		"partialRaw":   {"r12", []interface{}{[]byte{0xab, 0xcd}}, []interface{}{[]byte{0xab, 0xc0}}},
+		"littleEndian": {"<u16s32", []interface{}{0x1234, -3}, []interface{}{uint64(0x1234), int64(-3)}},
+		"littleFloat":  {"<f64", []interface{}{2.25}, []interface{}{2.25}},
+		"littleWide":   {"<u56u64", []interface{}{1, uint64(1) << 63}, []interface{}{uint64(1), uint64(1) << 63}},
+		"text":         {"t24", []interface{}{"abc"}, []interface{}{"abc"}},
+		"floats":       {"f32f64", []interface{}{float32(-1.5), 2.25}, []interface{}{float32(-1.5), 2.25}},
+		"signed64":     {"s64", []interface{}{-2}, []interface{}{int64(-2)}},
+		"half":         {"f16", []interface{}{0.5}, []interface{}{float32(0.5)}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba, err := PackFormat(tt.format, tt.in...)
+			if err != nil {
+				t.Fatalf("failed to pack: %s", err)
+			}
+			actual, err := UnpackFormat(tt.format, ba)
+			if err != nil {
+				t.Fatalf("failed to unpack: %s", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("got %#v, want %#v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnpackFormatShort(t *testing.T) {
+	if _, err := UnpackFormat("u8u1", NewFromBytes([]byte{0xff}, 8)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCompileFormatCached(t *testing.T) {
+	a, err := CompileFormat("u3s5")
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	b, _ := CompileFormat("u3s5")
+	if a != b {
+		t.Error("expected cached format")
+	}
+	if a.Len() != 8 {
+		t.Errorf("got len=%d, want 8", a.Len())
+	}
+}
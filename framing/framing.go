@@ -0,0 +1,131 @@
+// Package framing implements bit stuffing and HDLC frame delimiting on
+// BitArrays.
+package framing
+
+import (
+	"errors"
+	"fmt"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// ErrStuffing is returned when a stuff bit is missing or wrong.
+var ErrStuffing = errors.New("bit stuffing error")
+
+// Rule describes where stuff bits are inserted.
+type Rule struct {
+	// Number of consecutive bits after which a stuff bit is inserted
+	Run int
+	// If false only runs of ones count and the stuff bit is 0, otherwise runs
+	// of either value count and the stuff bit is the complement.
+	Both bool
+}
+
+// Stuffing rules.
+var (
+	// HDLC inserts a 0 after five consecutive 1s.
+	HDLC = Rule{Run: 5}
+	// CAN inserts the complement after five identical bits, the stuff bit
+	// counting towards the next run.
+	CAN = Rule{Run: 5, Both: true}
+	// USB inserts a 0 after six consecutive 1s.
+	USB = Rule{Run: 6}
+)
+
+// Stuff returns ba with stuff bits inserted.
+func Stuff(ba *bitarray.BitArray, rule Rule) *bitarray.BitArray {
+	out := bitarray.New()
+	run := 0
+	var last bool
+	for i := int64(0); i < ba.Len(); i++ {
+		b := ba.Test(i)
+		addBool(out, b)
+		if i > 0 && b == last {
+			run++
+		} else {
+			run = 1
+		}
+		last = b
+		if run == rule.Run && (rule.Both || b) {
+			// The stuff bit starts a new run
+			addBool(out, !b)
+			run, last = 1, !b
+		}
+	}
+	return out
+}
+
+// Unstuff returns ba with stuff bits removed. A stuff bit may be missing at
+// the end of ba.
+func Unstuff(ba *bitarray.BitArray, rule Rule) (*bitarray.BitArray, error) {
+	out := bitarray.New()
+	run := 0
+	var last, stuff bool
+	for i := int64(0); i < ba.Len(); i++ {
+		b := ba.Test(i)
+		if stuff {
+			if b == last {
+				return nil, fmt.Errorf("%w: at bit %d", ErrStuffing, i)
+			}
+			run, last, stuff = 1, b, false
+			continue
+		}
+		addBool(out, b)
+		if i > 0 && b == last {
+			run++
+		} else {
+			run = 1
+		}
+		last = b
+		stuff = run == rule.Run && (rule.Both || b)
+	}
+	return out, nil
+}
+
+// HDLCFlag is the frame delimiter 01111110.
+const HDLCFlag = 0x7e
+
+// FrameHDLC returns payload, bit stuffed, between two flags.
+func FrameHDLC(payload *bitarray.BitArray) *bitarray.BitArray {
+	out := bitarray.New()
+	out.AddN(HDLCFlag, 8)
+	stuffed := Stuff(payload, HDLC)
+	out.Append(*stuffed)
+	out.AddN(HDLCFlag, 8)
+	return out
+}
+
+// DeframeHDLC finds flags at any bit offset in ba and returns the unstuffed
+// contents between them. Empty frames between adjacent or shared flags, and
+// aborted frames with seven or more consecutive 1s, are skipped. Data before
+// the first flag and after the last is ignored.
+func DeframeHDLC(ba *bitarray.BitArray) []*bitarray.BitArray {
+	var out []*bitarray.BitArray
+	var reg uint
+	start := int64(-1)
+	for i := int64(0); i < ba.Len(); i++ {
+		reg = (reg << 1) & 0xff
+		if ba.Test(i) {
+			reg |= 1
+		}
+		if i < 7 || reg != HDLCFlag {
+			continue
+		}
+		if end := i - 7; start >= 0 && end > start {
+			content, _ := ba.Slice(start, end-start)
+			if frame, err := Unstuff(content, HDLC); err == nil {
+				out = append(out, frame)
+			}
+		}
+		start = i + 1
+	}
+	return out
+}
+
+func addBool(ba *bitarray.BitArray, b bool) {
+	if b {
+		ba.AddBit(1)
+	} else {
+		ba.AddBit(0)
+	}
+}
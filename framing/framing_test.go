@@ -0,0 +1,106 @@
+package framing
+
+import (
+	"errors"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestStuff(t *testing.T) {
+	tests := map[string]struct {
+		rule     Rule
+		in       string
+		expected string
+	}{
+		"hdlcFlag":     {HDLC, "01111110", "011111010"},
+		"hdlcFive":     {HDLC, "11111", "111110"},
+		"hdlcLong":     {HDLC, "111111111111", "11111011111011"},
+		"hdlcZeros":    {HDLC, "0000000000", "0000000000"},
+		"canZeros":     {CAN, "0000000000", "000001000001"},
+		"canStuffRun":  {CAN, "000001111", "00000111110"},
+		"canAlternate": {CAN, "0101010101", "0101010101"},
+		"usb":          {USB, "11111111", "111111011"},
+		"usbFive":      {USB, "111110", "111110"},
+		"empty":        {HDLC, "", ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			expected, _ := bitarray.NewFromString(tt.expected)
+			out := Stuff(in, tt.rule)
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+			data, err := Unstuff(out, tt.rule)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if data.String() != in.String() {
+				t.Errorf("got %s, want %s", data, in)
+			}
+		})
+	}
+}
+
+func TestUnstuffErrors(t *testing.T) {
+	tests := map[string]struct {
+		rule Rule
+		in   string
+	}{
+		"hdlcSixOnes":  {HDLC, "0111111"},
+		"canSixZeros":  {CAN, "1000000"},
+		"usbSevenOnes": {USB, "1111111"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			if _, err := Unstuff(in, tt.rule); !errors.Is(err, ErrStuffing) {
+				t.Errorf("got %v, want %v", err, ErrStuffing)
+			}
+		})
+	}
+
+	// A missing final stuff bit is accepted
+	in, _ := bitarray.NewFromString("11111")
+	if data, err := Unstuff(in, HDLC); err != nil || data.String() != "[11111---]" {
+		t.Errorf("got %v %v, want [11111---]", data, err)
+	}
+}
+
+func TestDeframeHDLC(t *testing.T) {
+	a := bitarray.NewFromBytes([]byte{0x7e, 0xff, 0x01}, 24)
+	b := bitarray.NewFromBytes([]byte{0x3f, 0xc0}, 16)
+	stream := bitarray.New()
+	// Idle ones and an offset of three bits
+	stream.AddN(0x7, 3)
+	stream.Append(*FrameHDLC(a))
+	// Back to back flags between frames
+	stream.AddN(HDLCFlag, 8)
+	stream.Append(*FrameHDLC(b))
+	// An aborted frame
+	aborted, _ := bitarray.NewFromString("1010 1111111 0 01111110")
+	stream.Append(*aborted)
+	// A flag sharing its zero with the previous flag, then a frame
+	shared, _ := bitarray.NewFromString("1111110 0110 01111110")
+	stream.Append(*shared)
+	stream.AddN(0x1f, 5)
+
+	frames := DeframeHDLC(stream)
+	expected := []string{a.String(), b.String(), "[0110----]"}
+	if len(frames) != len(expected) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(expected))
+	}
+	for i, f := range frames {
+		if actual := f.String(); actual != expected[i] {
+			t.Errorf("frame %d: got %s, want %s", i, actual, expected[i])
+		}
+	}
+
+	idle, _ := bitarray.NewFromString("0111111011111111")
+	if frames := DeframeHDLC(idle); len(frames) != 0 {
+		t.Errorf("got %d frames, want 0", len(frames))
+	}
+}
@@ -0,0 +1,265 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// HashKind identifies a hash function for HashBits.
+type HashKind int
+
+// Supported hash functions.
+const (
+	SHA256 HashKind = iota
+	SHA384
+	SHA512
+	SHA3_224
+	SHA3_256
+	SHA3_384
+	SHA3_512
+)
+
+// HashBits returns the digest of the bits of ba, which need not be a multiple
+// of 8, using the padding of FIPS 180-4 or FIPS 202. It returns nil for an
+// unknown kind.
+//
+// SHA-2 takes the bits MSB first. SHA-3 takes each whole byte as a byte, as
+// in FIPS 202, so byte aligned messages match other implementations. A
+// trailing partial byte of m bits is read as an m bit value and its bits are
+// taken LSB first, the convention of the NIST SHA-3 bit-oriented examples.
+func HashBits(h HashKind, ba *BitArray) []byte {
+	switch h {
+	case SHA256:
+		return sha256Bits(ba)
+	case SHA384:
+		return sha512Bits(ba, &sha384IV)[:48]
+	case SHA512:
+		return sha512Bits(ba, &sha512IV)
+	case SHA3_224:
+		return sha3Bits(ba, 28)
+	case SHA3_256:
+		return sha3Bits(ba, 32)
+	case SHA3_384:
+		return sha3Bits(ba, 48)
+	case SHA3_512:
+		return sha3Bits(ba, 64)
+	}
+	return nil
+}
+
+// Pad the message with a one bit, zeros, and its bit length in lenBytes to a
+// multiple of blockSize bytes.
+func sha2Pad(ba *BitArray, blockSize, lenBytes int) []byte {
+	b := ba.ASN1().Bytes
+	if r := ba.size % 8; r == 0 {
+		b = append(b, 0x80)
+	} else {
+		b[len(b)-1] |= 0x80 >> uint(r)
+	}
+	for len(b)%blockSize != blockSize-lenBytes {
+		b = append(b, 0)
+	}
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(ba.size))
+	for i := 8; i < lenBytes; i++ {
+		b = append(b, 0)
+	}
+	return append(b, l[:]...)
+}
+
+var sha256K = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+var sha256IV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+func sha256Bits(ba *BitArray) []byte {
+	h := sha256IV
+	var w [64]uint32
+	msg := sha2Pad(ba, 64, 8)
+	for ; len(msg) > 0; msg = msg[64:] {
+		for i := 0; i < 16; i++ {
+			w[i] = binary.BigEndian.Uint32(msg[i*4:])
+		}
+		for i := 16; i < 64; i++ {
+			s0 := bits.RotateLeft32(w[i-15], -7) ^ bits.RotateLeft32(w[i-15], -18) ^ w[i-15]>>3
+			s1 := bits.RotateLeft32(w[i-2], -17) ^ bits.RotateLeft32(w[i-2], -19) ^ w[i-2]>>10
+			w[i] = w[i-16] + s0 + w[i-7] + s1
+		}
+		a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+		for i := 0; i < 64; i++ {
+			s1 := bits.RotateLeft32(e, -6) ^ bits.RotateLeft32(e, -11) ^ bits.RotateLeft32(e, -25)
+			t1 := hh + s1 + (e&f ^ ^e&g) + sha256K[i] + w[i]
+			s0 := bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)
+			t2 := s0 + (a&b ^ a&c ^ b&c)
+			hh, g, f, e, d, c, b, a = g, f, e, d+t1, c, b, a, t1+t2
+		}
+		h[0] += a
+		h[1] += b
+		h[2] += c
+		h[3] += d
+		h[4] += e
+		h[5] += f
+		h[6] += g
+		h[7] += hh
+	}
+	out := make([]byte, 32)
+	for i, v := range h {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+	return out
+}
+
+var sha512K = [80]uint64{
+	0x428a2f98d728ae22, 0x7137449123ef65cd, 0xb5c0fbcfec4d3b2f, 0xe9b5dba58189dbbc,
+	0x3956c25bf348b538, 0x59f111f1b605d019, 0x923f82a4af194f9b, 0xab1c5ed5da6d8118,
+	0xd807aa98a3030242, 0x12835b0145706fbe, 0x243185be4ee4b28c, 0x550c7dc3d5ffb4e2,
+	0x72be5d74f27b896f, 0x80deb1fe3b1696b1, 0x9bdc06a725c71235, 0xc19bf174cf692694,
+	0xe49b69c19ef14ad2, 0xefbe4786384f25e3, 0x0fc19dc68b8cd5b5, 0x240ca1cc77ac9c65,
+	0x2de92c6f592b0275, 0x4a7484aa6ea6e483, 0x5cb0a9dcbd41fbd4, 0x76f988da831153b5,
+	0x983e5152ee66dfab, 0xa831c66d2db43210, 0xb00327c898fb213f, 0xbf597fc7beef0ee4,
+	0xc6e00bf33da88fc2, 0xd5a79147930aa725, 0x06ca6351e003826f, 0x142929670a0e6e70,
+	0x27b70a8546d22ffc, 0x2e1b21385c26c926, 0x4d2c6dfc5ac42aed, 0x53380d139d95b3df,
+	0x650a73548baf63de, 0x766a0abb3c77b2a8, 0x81c2c92e47edaee6, 0x92722c851482353b,
+	0xa2bfe8a14cf10364, 0xa81a664bbc423001, 0xc24b8b70d0f89791, 0xc76c51a30654be30,
+	0xd192e819d6ef5218, 0xd69906245565a910, 0xf40e35855771202a, 0x106aa07032bbd1b8,
+	0x19a4c116b8d2d0c8, 0x1e376c085141ab53, 0x2748774cdf8eeb99, 0x34b0bcb5e19b48a8,
+	0x391c0cb3c5c95a63, 0x4ed8aa4ae3418acb, 0x5b9cca4f7763e373, 0x682e6ff3d6b2b8a3,
+	0x748f82ee5defb2fc, 0x78a5636f43172f60, 0x84c87814a1f0ab72, 0x8cc702081a6439ec,
+	0x90befffa23631e28, 0xa4506cebde82bde9, 0xbef9a3f7b2c67915, 0xc67178f2e372532b,
+	0xca273eceea26619c, 0xd186b8c721c0c207, 0xeada7dd6cde0eb1e, 0xf57d4f7fee6ed178,
+	0x06f067aa72176fba, 0x0a637dc5a2c898a6, 0x113f9804bef90dae, 0x1b710b35131c471b,
+	0x28db77f523047d84, 0x32caab7b40c72493, 0x3c9ebe0a15c9bebc, 0x431d67c49c100d4c,
+	0x4cc5d4becb3e42b6, 0x597f299cfc657e2a, 0x5fcb6fab3ad6faec, 0x6c44198c4a475817,
+}
+
+var sha512IV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var sha384IV = [8]uint64{
+	0xcbbb9d5dc1059ed8, 0x629a292a367cd507, 0x9159015a3070dd17, 0x152fecd8f70e5939,
+	0x67332667ffc00b31, 0x8eb44a8768581511, 0xdb0c2e0d64f98fa7, 0x47b5481dbefa4fa4,
+}
+
+func sha512Bits(ba *BitArray, iv *[8]uint64) []byte {
+	h := *iv
+	var w [80]uint64
+	msg := sha2Pad(ba, 128, 16)
+	for ; len(msg) > 0; msg = msg[128:] {
+		for i := 0; i < 16; i++ {
+			w[i] = binary.BigEndian.Uint64(msg[i*8:])
+		}
+		for i := 16; i < 80; i++ {
+			s0 := bits.RotateLeft64(w[i-15], -1) ^ bits.RotateLeft64(w[i-15], -8) ^ w[i-15]>>7
+			s1 := bits.RotateLeft64(w[i-2], -19) ^ bits.RotateLeft64(w[i-2], -61) ^ w[i-2]>>6
+			w[i] = w[i-16] + s0 + w[i-7] + s1
+		}
+		a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+		for i := 0; i < 80; i++ {
+			s1 := bits.RotateLeft64(e, -14) ^ bits.RotateLeft64(e, -18) ^ bits.RotateLeft64(e, -41)
+			t1 := hh + s1 + (e&f ^ ^e&g) + sha512K[i] + w[i]
+			s0 := bits.RotateLeft64(a, -28) ^ bits.RotateLeft64(a, -34) ^ bits.RotateLeft64(a, -39)
+			t2 := s0 + (a&b ^ a&c ^ b&c)
+			hh, g, f, e, d, c, b, a = g, f, e, d+t1, c, b, a, t1+t2
+		}
+		h[0] += a
+		h[1] += b
+		h[2] += c
+		h[3] += d
+		h[4] += e
+		h[5] += f
+		h[6] += g
+		h[7] += hh
+	}
+	out := make([]byte, 64)
+	for i, v := range h {
+		binary.BigEndian.PutUint64(out[i*8:], v)
+	}
+	return out
+}
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// Rotation offsets indexed by x+5y.
+var keccakRho = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// Keccak-f[1600] permutation.
+func keccakF(a *[25]uint64) {
+	var b [25]uint64
+	var c, d [5]uint64
+	for round := 0; round < 24; round++ {
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for i := range a {
+			a[i] ^= d[i%5]
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], keccakRho[x+5*y])
+			}
+		}
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[x+y] = b[x+y] ^ ^b[(x+1)%5+y]&b[(x+2)%5+y]
+			}
+		}
+		a[0] ^= keccakRC[round]
+	}
+}
+
+// SHA-3 with a digest of size bytes.
+func sha3Bits(ba *BitArray, size int) []byte {
+	rate := 200 - 2*size
+	n := ba.size
+	// Message bits, the 01 domain suffix, and pad10*1 of at least two bits
+	blocks := (n + 4 + int64(rate)*8 - 1) / (int64(rate) * 8)
+	msg := make([]byte, blocks*int64(rate))
+	copy(msg, ba.raw[:n/8])
+	if r := uint(n % 8); r != 0 {
+		msg[n/8] = ba.raw[n/8] >> (8 - r)
+	}
+	for _, i := range []int64{n + 1, n + 2, int64(len(msg))*8 - 1} {
+		msg[i/8] ^= 1 << uint(i%8)
+	}
+
+	var a [25]uint64
+	for ; len(msg) > 0; msg = msg[rate:] {
+		for i := 0; i < rate/8; i++ {
+			a[i] ^= binary.LittleEndian.Uint64(msg[i*8:])
+		}
+		keccakF(&a)
+	}
+	out := make([]byte, 200)
+	for i, v := range a {
+		binary.LittleEndian.PutUint64(out[i*8:], v)
+	}
+	return out[:size]
+}
@@ -0,0 +1,80 @@
+package bitarray
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+func TestSHA2Pad(t *testing.T) {
+	// 5 bits, a one bit, zeros, then the length 5
+	b := sha2Pad(NewFromBytes([]byte{0xff}, 5), 64, 8)
+	expected := make([]byte, 64)
+	expected[0] = 0xfc
+	expected[63] = 5
+	if !bytes.Equal(b, expected) {
+		t.Errorf("got %x, want %x", b, expected)
+	}
+	// 448 bits leave no room for the length
+	if b := sha2Pad(NewFromBytes(make([]byte, 56), 448), 64, 8); len(b) != 128 {
+		t.Errorf("got len=%d, want 128", len(b))
+	}
+}
+
+func TestHashBitsSHA2Bytes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 300; n++ {
+		b := make([]byte, n)
+		rnd.Read(b)
+		ba := NewFromBytes(b, int64(n*8))
+		s256 := sha256.Sum256(b)
+		s384 := sha512.Sum384(b)
+		s512 := sha512.Sum512(b)
+		for _, c := range []struct {
+			h        HashKind
+			expected []byte
+		}{{SHA256, s256[:]}, {SHA384, s384[:]}, {SHA512, s512[:]}} {
+			if actual := HashBits(c.h, ba); !bytes.Equal(actual, c.expected) {
+				t.Errorf("%d len %d: got %x, want %x", c.h, n, actual, c.expected)
+			}
+		}
+	}
+}
+
+func TestHashBits(t *testing.T) {
+	tests := map[string]struct {
+		h        HashKind
+		in       *BitArray
+		expected string
+	}{
+		"sha3-224Empty": {SHA3_224, New(), "6b4e03423667dbb73b6e15454f0eb1abd4597f9a1b078e3f5b5a6bc7"},
+		"sha3-224":      {SHA3_224, NewFromBytes([]byte("abc"), 24), "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+		"sha3-224Long":  {SHA3_224, NewFromBytes(bytes.Repeat([]byte{0xa3}, 200), 1600), "9376816aba503f72f96ce7eb65ac095deee3be4bf9bbc2a1cb7e11e0"},
+		"sha3-256Empty": {SHA3_256, New(), "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		"sha3-256":      {SHA3_256, NewFromBytes([]byte("abc"), 24), "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		"sha3-256Long":  {SHA3_256, NewFromBytes(bytes.Repeat([]byte{0xa3}, 200), 1600), "79f38adec5c20307a98ef76e8324afbfd46cfd81b22e3973c65fa1bd9de31787"},
+		"sha3-384":      {SHA3_384, NewFromBytes([]byte("abc"), 24), "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+		"sha3-384Long":  {SHA3_384, NewFromBytes(bytes.Repeat([]byte{0xa3}, 200), 1600), "1881de2ca7e41ef95dc4732b8f5f002b189cc1e42b74168ed1732649ce1dbcdd76197a31fd55ee989f2d7050dd473e8f"},
+		"sha3-512":      {SHA3_512, NewFromBytes([]byte("abc"), 24), "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		"sha3-512Long":  {SHA3_512, NewFromBytes(bytes.Repeat([]byte{0xa3}, 200), 1600), "e76dfad22084a8b1467fcf2ffa58361bec7628edf5f3fdc0e4805dc48caeeca81b7c13c30adf52a3659584739a2df46be589c51ca1a4a8416df6545a1ce8ba00"},
+		// NIST SHA-3 example, 5 bit message 11001
+		"sha3-256Bits5": {SHA3_256, NewFromBytes([]byte{0x98}, 5), "7b0047cf5a456882363cbf0fb05322cf65f4b7059a46365e830132e3b5d957af"},
+		// SHAVS bit-oriented, 1 bit message 0
+		"sha256Bits1": {SHA256, NewFromBytes([]byte{0x00}, 1), "bd4f9e98beb68c6ead3243b1b4c7fed75fa4feaab1f84795cbd8a98676a2a375"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := hex.EncodeToString(HashBits(tt.h, tt.in)); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+
+	if HashBits(HashKind(-1), New()) != nil {
+		t.Error("expected nil for unknown kind")
+	}
+}
@@ -0,0 +1,106 @@
+// Package lfsr generates linear feedback shift register sequences, such as
+// PRBS test patterns, and applies additive and multiplicative scramblers to
+// BitArrays.
+package lfsr
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"src.userspace.com.au/bitarray"
+)
+
+var (
+	// ErrPoly is returned for feedback polynomials of degree less than 2.
+	ErrPoly = errors.New("invalid polynomial")
+	// ErrSeed is returned for a zero seed, which never leaves the all zero
+	// state.
+	ErrSeed = errors.New("invalid seed")
+)
+
+// Feedback polynomials of the ITU-T O.150 PRBS patterns. Bit k is the
+// coefficient of x^k, so PRBS7 is x^7 + x^6 + 1.
+const (
+	PRBS7  uint64 = 1<<7 | 1<<6 | 1
+	PRBS9  uint64 = 1<<9 | 1<<5 | 1
+	PRBS15 uint64 = 1<<15 | 1<<14 | 1
+	PRBS23 uint64 = 1<<23 | 1<<18 | 1
+	PRBS31 uint64 = 1<<31 | 1<<28 | 1
+)
+
+// LFSR is a linear feedback shift register of degree n. Bit 0 of the state
+// holds the most recent output.
+type LFSR struct {
+	poly   uint64
+	taps   uint64
+	mask   uint64
+	n      uint
+	state  uint64
+	galois bool
+}
+
+// NewFibonacci creates a Fibonacci LFSR. Each step outputs the XOR of the
+// outputs k steps earlier for every term x^k of poly with k > 0, and shifts
+// it into the state.
+func NewFibonacci(poly, seed uint64) (*LFSR, error) {
+	return newLFSR(poly, seed, false)
+}
+
+// NewGalois creates a Galois LFSR. Each step outputs the high bit of the
+// state, shifts left, and when the output is 1 adds the low terms of poly.
+func NewGalois(poly, seed uint64) (*LFSR, error) {
+	return newLFSR(poly, seed, true)
+}
+
+func newLFSR(poly, seed uint64, galois bool) (*LFSR, error) {
+	n := uint(bits.Len64(poly))
+	if n < 3 {
+		return nil, fmt.Errorf("%w: %#x", ErrPoly, poly)
+	}
+	n--
+	mask := uint64(1)<<n - 1
+	if seed&mask == 0 {
+		return nil, fmt.Errorf("%w: %#x", ErrSeed, seed)
+	}
+	return &LFSR{poly: poly, taps: poly >> 1 & mask, mask: mask, n: n, state: seed & mask, galois: galois}, nil
+}
+
+// NewPRBS creates a Fibonacci LFSR for poly seeded with all ones.
+func NewPRBS(poly uint64) (*LFSR, error) {
+	return NewFibonacci(poly, ^uint64(0))
+}
+
+// Degree returns the register length.
+func (l *LFSR) Degree() int {
+	return int(l.n)
+}
+
+// State returns the register contents.
+func (l *LFSR) State() uint64 {
+	return l.state
+}
+
+// Next steps the register and returns the output bit.
+func (l *LFSR) Next() uint {
+	if l.galois {
+		out := l.state >> (l.n - 1) & 1
+		l.state = l.state << 1 & l.mask
+		if out == 1 {
+			l.state ^= l.poly & l.mask
+		}
+		return uint(out)
+	}
+	out := uint64(bits.OnesCount64(l.state&l.taps) & 1)
+	l.state = (l.state<<1 | out) & l.mask
+	return uint(out)
+}
+
+// Read returns the next n output bits.
+func (l *LFSR) Read(n int64) *bitarray.BitArray {
+	out := bitarray.New()
+	for i := int64(0); i < n; i++ {
+		out.AddBit(l.Next())
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package lfsr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPeriod(t *testing.T) {
+	tests := map[string]struct {
+		poly   uint64
+		galois bool
+	}{
+		"prbs7":        {PRBS7, false},
+		"prbs9":        {PRBS9, false},
+		"prbs15":       {PRBS15, false},
+		"prbs7Galois":  {PRBS7, true},
+		"prbs9Galois":  {PRBS9, true},
+		"prbs15Galois": {PRBS15, true},
+		"ieee80211":    {IEEE80211.Poly, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var l *LFSR
+			var err error
+			if tt.galois {
+				l, err = NewGalois(tt.poly, 1)
+			} else {
+				l, err = NewFibonacci(tt.poly, 1)
+			}
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			period := 1<<uint(l.Degree()) - 1
+			start := l.State()
+			ones := 0
+			for i := 1; i <= period; i++ {
+				ones += int(l.Next())
+				if l.State() == start && i != period {
+					t.Fatalf("got period %d, want %d", i, period)
+				}
+			}
+			if l.State() != start {
+				t.Errorf("got state %#x after %d, want %#x", l.State(), period, start)
+			}
+			// A maximal length sequence has one more one than zeros
+			if ones != (period+1)/2 {
+				t.Errorf("got %d ones, want %d", ones, (period+1)/2)
+			}
+		})
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	// Each output is the XOR of the outputs n and k steps earlier
+	tests := map[string]struct {
+		poly uint64
+		n, k int
+	}{
+		"prbs23": {PRBS23, 23, 18},
+		"prbs31": {PRBS31, 31, 28},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := NewPRBS(tt.poly)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			seq := l.Read(1000)
+			for i := int64(tt.n); i < seq.Len(); i++ {
+				if seq.Test(i) != (seq.Test(i-int64(tt.n)) != seq.Test(i-int64(tt.k))) {
+					t.Fatalf("recurrence fails at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	// IEEE 802.11 scrambler sequence from the all ones state
+	l, _ := NewFibonacci(IEEE80211.Poly, 0x7f)
+	expected := "[00001110 11110010 11001001 00000010 00100110 00101110 10110110 00001100 " +
+		"11010100 11100111 10110100 00101010 11111010 01010001 10111000 1111111-]"
+	if actual := l.Read(127).String(); actual != expected {
+		t.Errorf("got %s, want %s", actual, expected)
+	}
+	if actual := l.Read(0).Len(); actual != 0 {
+		t.Errorf("got len=%d, want 0", actual)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := NewFibonacci(PRBS7, 0x80); !errors.Is(err, ErrSeed) {
+		t.Errorf("got %v, want %v", err, ErrSeed)
+	}
+	if _, err := NewGalois(0x3, 1); !errors.Is(err, ErrPoly) {
+		t.Errorf("got %v, want %v", err, ErrPoly)
+	}
+	if _, err := NewFibonacci(0, 1); !errors.Is(err, ErrPoly) {
+		t.Errorf("got %v, want %v", err, ErrPoly)
+	}
+	if _, err := NewFibonacci(1, 1); !errors.Is(err, ErrPoly) {
+		t.Errorf("got %v, want %v", err, ErrPoly)
+	}
+}
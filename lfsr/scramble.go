@@ -0,0 +1,97 @@
+package lfsr
+
+import (
+	"fmt"
+	"math/bits"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// Scrambler whitens data with an LFSR sequence.
+//
+// An additive, or synchronous, scrambler adds the output of a Fibonacci LFSR
+// seeded with Seed, so scrambling and descrambling are the same operation.
+//
+// A multiplicative, or self-synchronising, scrambler feeds the scrambled bits
+// back through the taps of Poly instead, so the descrambler recovers after
+// Degree bits whatever its Seed.
+type Scrambler struct {
+	Poly           uint64
+	Seed           uint64
+	Multiplicative bool
+}
+
+// Scrambler presets.
+var (
+	// IEEE 802.11 OFDM data scrambler, x^7 + x^4 + 1. The seed is chosen per
+	// frame.
+	IEEE80211 = Scrambler{Poly: 1<<7 | 1<<4 | 1, Seed: 0x7f}
+	// DVB energy dispersal, x^15 + x^14 + 1 initialised to 100101010000000.
+	DVB = Scrambler{Poly: 1<<15 | 1<<14 | 1, Seed: 0x00a9}
+	// IEEE 802.3 64b/66b payload scrambler, x^58 + x^39 + 1.
+	Ethernet64B66B = Scrambler{Poly: 1<<58 | 1<<39 | 1, Multiplicative: true}
+)
+
+// Scramble scrambles all bits of ba in place.
+func (s Scrambler) Scramble(ba *bitarray.BitArray) error {
+	return s.ScrambleRange(ba, 0, ba.Len())
+}
+
+// Descramble descrambles all bits of ba in place.
+func (s Scrambler) Descramble(ba *bitarray.BitArray) error {
+	return s.DescrambleRange(ba, 0, ba.Len())
+}
+
+// ScrambleRange scrambles length bits of ba from start in place.
+func (s Scrambler) ScrambleRange(ba *bitarray.BitArray, start, length int64) error {
+	return s.apply(ba, start, length, false)
+}
+
+// DescrambleRange descrambles length bits of ba from start in place.
+func (s Scrambler) DescrambleRange(ba *bitarray.BitArray, start, length int64) error {
+	return s.apply(ba, start, length, true)
+}
+
+func (s Scrambler) apply(ba *bitarray.BitArray, start, length int64, descramble bool) error {
+	if start < 0 || length < 0 || start+length > ba.Len() {
+		return fmt.Errorf("bit range %d+%d out of bounds of %d", start, length, ba.Len())
+	}
+	if !s.Multiplicative {
+		l, err := NewFibonacci(s.Poly, s.Seed)
+		if err != nil {
+			return err
+		}
+		for i := start; i < start+length; i++ {
+			if l.Next() == 1 {
+				ba.Flip(i)
+			}
+		}
+		return nil
+	}
+
+	n := uint(bits.Len64(s.Poly))
+	if n < 3 {
+		return fmt.Errorf("%w: %#x", ErrPoly, s.Poly)
+	}
+	n--
+	mask := uint64(1)<<n - 1
+	taps := s.Poly >> 1 & mask
+	state := s.Seed & mask
+	for i := start; i < start+length; i++ {
+		in := ba.Test(i)
+		if bits.OnesCount64(state&taps)&1 == 1 {
+			ba.Flip(i)
+		}
+		// The state holds scrambled bits
+		scrambled := ba.Test(i)
+		if descramble {
+			scrambled = in
+		}
+		state <<= 1
+		if scrambled {
+			state |= 1
+		}
+		state &= mask
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package lfsr
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestScrambleDVB(t *testing.T) {
+	// Scrambling zeros gives the PRBS
+	ba := bitarray.New(bitarray.SetSize(64))
+	if err := DVB.Scramble(ba); err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	expected := []byte{0x03, 0xf6, 0x08, 0x34, 0x30, 0xb8, 0xa3, 0x93}
+	if !bytes.Equal(ba.Bytes(), expected) {
+		t.Errorf("got %x, want %x", ba.Bytes(), expected)
+	}
+}
+
+func TestScrambleRoundTrip(t *testing.T) {
+	tests := map[string]Scrambler{
+		"ieee80211":      {Poly: IEEE80211.Poly, Seed: 0x5d},
+		"dvb":            DVB,
+		"ethernet64b66b": Ethernet64B66B,
+		"seeded":         {Poly: Ethernet64B66B.Poly, Seed: 0x123456789, Multiplicative: true},
+	}
+
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			b := make([]byte, 40)
+			rnd.Read(b)
+			orig := bitarray.NewFromBytes(append([]byte(nil), b...), 317)
+			ba := bitarray.NewFromBytes(b, 317)
+			if err := s.ScrambleRange(ba, 5, 300); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if ba.String() == orig.String() {
+				t.Error("expected scrambled data to differ")
+			}
+			for _, i := range []int64{0, 4, 305, 316} {
+				if ba.Test(i) != orig.Test(i) {
+					t.Errorf("bit %d outside range changed", i)
+				}
+			}
+			if err := s.DescrambleRange(ba, 5, 300); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if ba.String() != orig.String() {
+				t.Errorf("got %s, want %s", ba, orig)
+			}
+		})
+	}
+}
+
+func TestMultiplicativeSelfSync(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	b := make([]byte, 32)
+	rnd.Read(b)
+	orig := bitarray.NewFromBytes(append([]byte(nil), b...), 256)
+	ba := bitarray.NewFromBytes(b, 256)
+	s := Ethernet64B66B
+	s.Scramble(ba)
+
+	// A descrambler with the wrong state recovers after 58 bits
+	s.Seed = 0xdeadbeef
+	s.Descramble(ba)
+	tail, _ := ba.Slice(58, 198)
+	expected, _ := orig.Slice(58, 198)
+	if tail.String() != expected.String() {
+		t.Errorf("got %s, want %s", tail, expected)
+	}
+}
+
+func TestScrambleErrors(t *testing.T) {
+	ba := bitarray.New(bitarray.SetSize(8))
+	if err := IEEE80211.ScrambleRange(ba, 4, 8); err == nil {
+		t.Error("expected range error")
+	}
+	if err := (Scrambler{Poly: PRBS7}).Scramble(ba); !errors.Is(err, ErrSeed) {
+		t.Errorf("got %v, want %v", err, ErrSeed)
+	}
+	if err := (Scrambler{Poly: 1, Multiplicative: true}).Scramble(ba); !errors.Is(err, ErrPoly) {
+		t.Errorf("got %v, want %v", err, ErrPoly)
+	}
+}
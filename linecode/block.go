@@ -0,0 +1,250 @@
+package linecode
+
+import (
+	"math/bits"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// 4b5b data codes, as used by FDDI and 100BASE-X.
+var fourB5B = [16]uint{
+	0x1e, 0x09, 0x14, 0x15, 0x0a, 0x0b, 0x0e, 0x0f,
+	0x12, 0x13, 0x16, 0x17, 0x1a, 0x1b, 0x1c, 0x1d,
+}
+
+// 4b5b control codes.
+const (
+	Code4B5BQuiet uint = 0x00
+	Code4B5BIdle  uint = 0x1f
+	Code4B5BJ     uint = 0x18
+	Code4B5BK     uint = 0x11
+	Code4B5BT     uint = 0x0d
+	Code4B5BR     uint = 0x07
+	Code4B5BSet   uint = 0x19
+	Code4B5BHalt  uint = 0x04
+)
+
+// Encode4B5B returns a 5 bit code for each 4 bits of ba.
+func Encode4B5B(ba *bitarray.BitArray) (*bitarray.BitArray, error) {
+	if ba.Len()%4 != 0 {
+		return nil, &Error{Pos: ba.Len() / 4 * 4, Err: ErrLength}
+	}
+	out := bitarray.New()
+	for i := int64(0); i < ba.Len(); i += 4 {
+		v, err := ba.ReadUint(i, 4)
+		if err != nil {
+			return nil, err
+		}
+		out.AddN(fourB5B[v], 5)
+	}
+	return out, nil
+}
+
+// Decode4B5B returns the 4 data bits of each 5 bit code in ba. Control codes
+// are invalid.
+func Decode4B5B(ba *bitarray.BitArray) (*bitarray.BitArray, error) {
+	if ba.Len()%5 != 0 {
+		return nil, &Error{Pos: ba.Len() / 5 * 5, Err: ErrLength}
+	}
+	out := bitarray.New()
+	for i := int64(0); i < ba.Len(); i += 5 {
+		v, err := ba.ReadUint(i, 5)
+		if err != nil {
+			return nil, err
+		}
+		d := -1
+		for j, c := range fourB5B {
+			if c == v {
+				d = j
+			}
+		}
+		if d < 0 {
+			return nil, &Error{Pos: i, Err: ErrInvalidSymbol}
+		}
+		out.AddN(uint(d), 4)
+	}
+	return out, nil
+}
+
+// 5b/6b codes for EDCBA with RD-, bits abcdei with a first.
+var fiveB6B = [32]uint{
+	0x27, 0x1d, 0x2d, 0x31, 0x35, 0x29, 0x19, 0x38,
+	0x39, 0x25, 0x15, 0x34, 0x0d, 0x2c, 0x1c, 0x17,
+	0x1b, 0x23, 0x13, 0x32, 0x0b, 0x2a, 0x1a, 0x3a,
+	0x33, 0x26, 0x16, 0x36, 0x0e, 0x2e, 0x1e, 0x2b,
+}
+
+// 3b/4b codes for HGF with RD-, bits fghj with f first. HGF of 7 is P7, A7
+// is used to avoid runs of five.
+var threeB4B = [8]uint{0xb, 0x9, 0x5, 0xc, 0xd, 0xa, 0x6, 0xe}
+
+const threeB4BA7 = 0x7
+
+// 8b10b control symbols with RD-, bits abcdeifghj. RD+ is the complement.
+var controls = map[byte]uint{
+	K28_0: 0x0f4, K28_1: 0x0f9, K28_2: 0x0f5, K28_3: 0x0f3,
+	K28_4: 0x0f2, K28_5: 0x0fa, K28_6: 0x0f6, K28_7: 0x0f8,
+	K23_7: 0x3a8, K27_7: 0x368, K29_7: 0x2e8, K30_7: 0x1e8,
+}
+
+// 8b10b control symbol values, Kx.y being the byte y<<5 | x.
+const (
+	K28_0 byte = 0x1c
+	K28_1 byte = 0x3c
+	K28_2 byte = 0x5c
+	K28_3 byte = 0x7c
+	K28_4 byte = 0x9c
+	K28_5 byte = 0xbc
+	K28_6 byte = 0xdc
+	K28_7 byte = 0xfc
+	K23_7 byte = 0xf7
+	K27_7 byte = 0xfb
+	K29_7 byte = 0xfd
+	K30_7 byte = 0xfe
+)
+
+// Decoding tables indexed by running disparity, 0 for RD- and 1 for RD+, and
+// 10 bit code.
+var decode8B10B [2][1024]struct {
+	valid   bool
+	control bool
+	value   byte
+	rdOut   bool
+}
+
+func init() {
+	for rd := 0; rd < 2; rd++ {
+		for v := 0; v < 256; v++ {
+			code, out := encodeData(byte(v), rd == 1)
+			e := &decode8B10B[rd][code]
+			e.valid, e.value, e.rdOut = true, byte(v), out
+		}
+		for v := range controls {
+			code, out, _ := encodeControl(v, rd == 1)
+			e := &decode8B10B[rd][code]
+			e.valid, e.control, e.value, e.rdOut = true, true, v, out
+		}
+	}
+}
+
+// Codec8B10B encodes and decodes 8b10b symbols, tracking the running
+// disparity. The zero value starts with RD-.
+type Codec8B10B struct {
+	positive bool
+}
+
+// Disparity returns the running disparity, -1 or 1.
+func (c *Codec8B10B) Disparity() int {
+	if c.positive {
+		return 1
+	}
+	return -1
+}
+
+// Encode returns a 10 bit data symbol for each byte of ba.
+func (c *Codec8B10B) Encode(ba *bitarray.BitArray) (*bitarray.BitArray, error) {
+	if ba.Len()%8 != 0 {
+		return nil, &Error{Pos: ba.Len() / 8 * 8, Err: ErrLength}
+	}
+	out := bitarray.New()
+	for i := int64(0); i < ba.Len(); i += 8 {
+		v, err := ba.ReadUint(i, 8)
+		if err != nil {
+			return nil, err
+		}
+		c.EncodeSymbol(out, byte(v), false)
+	}
+	return out, nil
+}
+
+// EncodeSymbol adds the 10 bit symbol for v to ba, as a control symbol if
+// control is true. It returns ErrInvalidSymbol for values that are not one of
+// the twelve control symbols.
+func (c *Codec8B10B) EncodeSymbol(ba *bitarray.BitArray, v byte, control bool) error {
+	var code uint
+	if control {
+		var ok bool
+		if code, c.positive, ok = encodeControl(v, c.positive); !ok {
+			return ErrInvalidSymbol
+		}
+	} else {
+		code, c.positive = encodeData(v, c.positive)
+	}
+	ba.AddN(code, 10)
+	return nil
+}
+
+// Decode returns the byte of each 10 bit symbol in ba, and the indexes of
+// symbols that are control symbols.
+func (c *Codec8B10B) Decode(ba *bitarray.BitArray) (*bitarray.BitArray, []int, error) {
+	if ba.Len()%10 != 0 {
+		return nil, nil, &Error{Pos: ba.Len() / 10 * 10, Err: ErrLength}
+	}
+	out := bitarray.New()
+	var controls []int
+	for i := int64(0); i < ba.Len(); i += 10 {
+		code, err := ba.ReadUint(i, 10)
+		if err != nil {
+			return nil, nil, err
+		}
+		rd := 0
+		if c.positive {
+			rd = 1
+		}
+		e := decode8B10B[rd][code]
+		if !e.valid {
+			if decode8B10B[rd^1][code].valid {
+				return nil, nil, &Error{Pos: i, Err: ErrDisparity}
+			}
+			return nil, nil, &Error{Pos: i, Err: ErrInvalidSymbol}
+		}
+		if e.control {
+			controls = append(controls, int(i/10))
+		}
+		out.AddN(uint(e.value), 8)
+		c.positive = e.rdOut
+	}
+	return out, controls, nil
+}
+
+// Encode a data byte, returning the symbol and the new running disparity.
+func encodeData(v byte, rd bool) (uint, bool) {
+	x, y := v&0x1f, v>>5
+	c6 := fiveB6B[x]
+	if rd && (bits.OnesCount(c6) != 3 || x == 7) {
+		c6 ^= 0x3f
+	}
+	rd = disparity(c6, 6, rd)
+	c4 := threeB4B[y]
+	if y == 7 && ((!rd && (x == 17 || x == 18 || x == 20)) || (rd && (x == 11 || x == 13 || x == 14))) {
+		c4 = threeB4BA7
+	}
+	if rd && (bits.OnesCount(c4) != 2 || y == 3) {
+		c4 ^= 0xf
+	}
+	return c6<<4 | c4, disparity(c4, 4, rd)
+}
+
+// Encode a control symbol, returning the symbol, the new running disparity
+// and false if v is not a control symbol.
+func encodeControl(v byte, rd bool) (uint, bool, bool) {
+	code, ok := controls[v]
+	if !ok {
+		return 0, rd, false
+	}
+	if rd {
+		code ^= 0x3ff
+	}
+	return code, disparity(code, 10, rd), true
+}
+
+// The running disparity after a sub-block of width bits.
+func disparity(code uint, width int, rd bool) bool {
+	switch ones := bits.OnesCount(code); {
+	case 2*ones > width:
+		return true
+	case 2*ones < width:
+		return false
+	}
+	return rd
+}
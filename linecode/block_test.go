@@ -0,0 +1,167 @@
+package linecode
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func Test4B5B(t *testing.T) {
+	out, err := Encode4B5B(bitarray.NewFromBytes([]byte{0x0f, 0x5a}, 16))
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := out.String(); actual != "[11110111 01010111 0110----]" {
+		t.Errorf("got %s, want [11110111 01010111 0110----]", actual)
+	}
+	data, err := Decode4B5B(out)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := data.String(); actual != "[00001111 01011010]" {
+		t.Errorf("got %s, want [00001111 01011010]", actual)
+	}
+
+	// A J symbol in data
+	in, _ := bitarray.NewFromString("11110 11000")
+	_, err = Decode4B5B(in)
+	var e *Error
+	if !errors.As(err, &e) || e.Pos != 5 || !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("got %v, want invalid symbol at bit 5", err)
+	}
+	short, _ := bitarray.NewFromString("101")
+	if _, err := Encode4B5B(short); !errors.Is(err, ErrLength) {
+		t.Errorf("got %v, want %v", err, ErrLength)
+	}
+}
+
+func Test8B10BSymbols(t *testing.T) {
+	tests := map[string]struct {
+		v        byte
+		control  bool
+		positive bool
+		expected string
+		rdOut    int
+	}{
+		"D.0.0-":  {0x00, false, false, "1001110100", -1},
+		"D.0.0+":  {0x00, false, true, "0110001011", 1},
+		"D.21.5-": {0xb5, false, false, "1010101010", -1},
+		"D.21.5+": {0xb5, false, true, "1010101010", 1},
+		"D.3.3-":  {0x63, false, false, "1100011100", -1},
+		"D.3.3+":  {0x63, false, true, "1100010011", 1},
+		"D.7.0-":  {0x07, false, false, "1110001011", 1},
+		"D.7.0+":  {0x07, false, true, "0001110100", -1},
+		"D.17.7-": {0xf1, false, false, "1000110111", 1},
+		"D.11.7+": {0xeb, false, true, "1101001000", -1},
+		"D.1.7+":  {0xe1, false, true, "1000101110", 1},
+		"K.28.5-": {K28_5, true, false, "0011111010", 1},
+		"K.28.5+": {K28_5, true, true, "1100000101", -1},
+		"K.28.0-": {K28_0, true, false, "0011110100", -1},
+		"K.23.7+": {K23_7, true, true, "0001010111", 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Codec8B10B{positive: tt.positive}
+			out := bitarray.New()
+			if err := c.EncodeSymbol(out, tt.v, tt.control); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			expected, _ := bitarray.NewFromString(tt.expected)
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+			if c.Disparity() != tt.rdOut {
+				t.Errorf("got RD %d, want %d", c.Disparity(), tt.rdOut)
+			}
+		})
+	}
+
+	if err := new(Codec8B10B).EncodeSymbol(bitarray.New(), 0x00, true); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("got %v, want %v", err, ErrInvalidSymbol)
+	}
+}
+
+func Test8B10BStream(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	b := make([]byte, 2000)
+	rnd.Read(b)
+	var enc Codec8B10B
+	code := bitarray.New()
+	enc.EncodeSymbol(code, K28_5, true)
+	data, err := enc.Encode(bitarray.NewFromBytes(b, int64(len(b)*8)))
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	code.Append(*data)
+	enc.EncodeSymbol(code, K28_7, true)
+	enc.EncodeSymbol(code, K30_7, true)
+
+	// Run length and running disparity
+	run, sum := 0, -1
+	for i := int64(0); i < code.Len(); i++ {
+		if i > 0 && code.Test(i) == code.Test(i-1) {
+			run++
+		} else {
+			run = 1
+		}
+		// K28.7 may give a run of five across symbols
+		if run > 5 {
+			t.Fatalf("got run of %d at bit %d", run, i)
+		}
+		if code.Test(i) {
+			sum++
+		} else {
+			sum--
+		}
+		if (i+1)%10 == 0 && sum != -1 && sum != 1 {
+			t.Fatalf("got disparity %d after symbol %d", sum, i/10)
+		}
+	}
+
+	var dec Codec8B10B
+	out, controls, err := dec.Decode(code)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if !reflect.DeepEqual(controls, []int{0, 2001, 2002}) {
+		t.Errorf("got controls %v", controls)
+	}
+	expected := append(append([]byte{K28_5}, b...), K28_7, K30_7)
+	if !reflect.DeepEqual(out.Bytes(), expected) {
+		t.Error("got wrong data")
+	}
+	if dec.Disparity() != enc.Disparity() {
+		t.Errorf("got RD %d, want %d", dec.Disparity(), enc.Disparity())
+	}
+}
+
+func Test8B10BDecodeErrors(t *testing.T) {
+	tests := map[string]struct {
+		in  string
+		pos int64
+		err error
+	}{
+		// K28.5 RD- twice
+		"disparity": {"0011111010 0011111010", 10, ErrDisparity},
+		"invalid":   {"1001110100 1111100000", 10, ErrInvalidSymbol},
+		"length":    {"1001110100 10", 10, ErrLength},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			_, _, err := new(Codec8B10B).Decode(in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.Pos != tt.pos {
+				t.Errorf("got %v, want position %d", err, tt.pos)
+			}
+		})
+	}
+}
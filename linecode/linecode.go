@@ -0,0 +1,173 @@
+// Package linecode converts between data bits and line bits for Manchester,
+// differential Manchester, NRZI, 4b5b and 8b10b codes.
+package linecode
+
+import (
+	"errors"
+	"fmt"
+
+	"src.userspace.com.au/bitarray"
+)
+
+var (
+	// ErrInvalidSymbol is returned for line bits that are not a valid code.
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrDisparity is returned for a valid 8b10b code with the wrong running
+	// disparity.
+	ErrDisparity = errors.New("running disparity error")
+	// ErrLength is returned for input that is not a whole number of symbols.
+	ErrLength = errors.New("invalid length")
+)
+
+// Error reports a decoding error. Pos is the bit offset in the input of the
+// start of the offending symbol, or of the trailing partial symbol for
+// ErrLength.
+type Error struct {
+	Pos int64
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at bit %d", e.Err, e.Pos)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Manchester is the line bit pair for a 0 data bit, the pair for a 1 being its
+// inverse.
+type Manchester int
+
+const (
+	// IEEE 802.3, 0 is high-low and 1 is low-high.
+	IEEE Manchester = iota
+	// G.E. Thomas, 0 is low-high and 1 is high-low.
+	Thomas
+)
+
+// Encode returns two line bits for each bit of ba.
+func (m Manchester) Encode(ba *bitarray.BitArray) *bitarray.BitArray {
+	out := bitarray.New()
+	for i := int64(0); i < ba.Len(); i++ {
+		first := uint(1)
+		if ba.Test(i) != (m == Thomas) {
+			first = 0
+		}
+		out.AddBit(first)
+		out.AddBit(first ^ 1)
+	}
+	return out
+}
+
+// Decode returns the data bit for each pair of line bits in ba.
+func (m Manchester) Decode(ba *bitarray.BitArray) (*bitarray.BitArray, error) {
+	if ba.Len()%2 != 0 {
+		return nil, &Error{Pos: ba.Len() - 1, Err: ErrLength}
+	}
+	out := bitarray.New()
+	for i := int64(0); i < ba.Len(); i += 2 {
+		first := ba.Test(i)
+		if first == ba.Test(i+1) {
+			return nil, &Error{Pos: i, Err: ErrInvalidSymbol}
+		}
+		if first == (m == Thomas) {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+	}
+	return out, nil
+}
+
+// DiffManchesterEncode returns differential Manchester line bits for ba,
+// starting from line level. Every bit has a mid-bit transition, and a 0 also
+// has a transition at its start.
+func DiffManchesterEncode(ba *bitarray.BitArray, level uint) *bitarray.BitArray {
+	out := bitarray.New()
+	level &= 1
+	for i := int64(0); i < ba.Len(); i++ {
+		if !ba.Test(i) {
+			level ^= 1
+		}
+		out.AddBit(level)
+		level ^= 1
+		out.AddBit(level)
+	}
+	return out
+}
+
+// DiffManchesterDecode returns the data bits of differential Manchester line
+// bits, where level is the line level before the first bit.
+func DiffManchesterDecode(ba *bitarray.BitArray, level uint) (*bitarray.BitArray, error) {
+	if ba.Len()%2 != 0 {
+		return nil, &Error{Pos: ba.Len() - 1, Err: ErrLength}
+	}
+	out := bitarray.New()
+	prev := level&1 == 1
+	for i := int64(0); i < ba.Len(); i += 2 {
+		first, second := ba.Test(i), ba.Test(i+1)
+		if first == second {
+			return nil, &Error{Pos: i, Err: ErrInvalidSymbol}
+		}
+		if first == prev {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+		prev = second
+	}
+	return out, nil
+}
+
+// NRZIEncode returns NRZI line bits for ba starting from line level, with a
+// transition for each 1, as NRZ-M.
+func NRZIEncode(ba *bitarray.BitArray, level uint) *bitarray.BitArray {
+	return nrziEncode(ba, level, true)
+}
+
+// NRZIDecode returns the data bits of NRZ-M line bits, where level is the line
+// level before the first bit.
+func NRZIDecode(ba *bitarray.BitArray, level uint) *bitarray.BitArray {
+	return nrziDecode(ba, level, true)
+}
+
+// NRZSEncode returns NRZ-S line bits for ba starting from line level, with a
+// transition for each 0, as used by USB.
+func NRZSEncode(ba *bitarray.BitArray, level uint) *bitarray.BitArray {
+	return nrziEncode(ba, level, false)
+}
+
+// NRZSDecode returns the data bits of NRZ-S line bits, where level is the line
+// level before the first bit.
+func NRZSDecode(ba *bitarray.BitArray, level uint) *bitarray.BitArray {
+	return nrziDecode(ba, level, false)
+}
+
+func nrziEncode(ba *bitarray.BitArray, level uint, mark bool) *bitarray.BitArray {
+	out := bitarray.New()
+	level &= 1
+	for i := int64(0); i < ba.Len(); i++ {
+		if ba.Test(i) == mark {
+			level ^= 1
+		}
+		out.AddBit(level)
+	}
+	return out
+}
+
+func nrziDecode(ba *bitarray.BitArray, level uint, mark bool) *bitarray.BitArray {
+	out := bitarray.New()
+	prev := level&1 == 1
+	for i := int64(0); i < ba.Len(); i++ {
+		cur := ba.Test(i)
+		if (cur != prev) == mark {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+		prev = cur
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package linecode
+
+import (
+	"errors"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestManchester(t *testing.T) {
+	tests := map[string]struct {
+		m        Manchester
+		in       string
+		expected string
+	}{
+		"ieee":   {IEEE, "1011", "01100101"},
+		"thomas": {Thomas, "1011", "10011010"},
+		"empty":  {IEEE, "", ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			expected, _ := bitarray.NewFromString(tt.expected)
+			out := tt.m.Encode(in)
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+			data, err := tt.m.Decode(out)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if data.String() != in.String() {
+				t.Errorf("got %s, want %s", data, in)
+			}
+		})
+	}
+}
+
+func TestDiffManchester(t *testing.T) {
+	in, _ := bitarray.NewFromString("0110")
+	out := DiffManchesterEncode(in, 0)
+	if actual := out.String(); actual != "[10011010]" {
+		t.Errorf("got %s, want [10011010]", actual)
+	}
+	data, err := DiffManchesterDecode(out, 0)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := data.String(); actual != "[0110----]" {
+		t.Errorf("got %s, want [0110----]", actual)
+	}
+	// Inverting the line gives the same data
+	inverted, _ := bitarray.NewFromString("01100101")
+	data, _ = DiffManchesterDecode(inverted, 1)
+	if actual := data.String(); actual != "[0110----]" {
+		t.Errorf("got %s, want [0110----]", actual)
+	}
+}
+
+func TestNRZI(t *testing.T) {
+	tests := map[string]struct {
+		encode   func(*bitarray.BitArray, uint) *bitarray.BitArray
+		decode   func(*bitarray.BitArray, uint) *bitarray.BitArray
+		in       string
+		level    uint
+		expected string
+	}{
+		"nrzi":        {NRZIEncode, NRZIDecode, "1101000", 0, "1001111"},
+		"nrziHigh":    {NRZIEncode, NRZIDecode, "1101000", 1, "0110000"},
+		"nrzsUSBSync": {NRZSEncode, NRZSDecode, "00000001", 1, "01010100"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			expected, _ := bitarray.NewFromString(tt.expected)
+			out := tt.encode(in, tt.level)
+			if out.String() != expected.String() {
+				t.Errorf("got %s, want %s", out, expected)
+			}
+			if data := tt.decode(out, tt.level); data.String() != in.String() {
+				t.Errorf("got %s, want %s", data, in)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := map[string]struct {
+		decode func(*bitarray.BitArray) error
+		in     string
+		pos    int64
+		err    error
+	}{
+		"manchester": {func(ba *bitarray.BitArray) error {
+			_, err := IEEE.Decode(ba)
+			return err
+		}, "0110 11", 4, ErrInvalidSymbol},
+		"manchesterLength": {func(ba *bitarray.BitArray) error {
+			_, err := Thomas.Decode(ba)
+			return err
+		}, "011", 2, ErrLength},
+		"diffManchester": {func(ba *bitarray.BitArray) error {
+			_, err := DiffManchesterDecode(ba, 0)
+			return err
+		}, "10 01 00", 4, ErrInvalidSymbol},
+		"diffManchesterLength": {func(ba *bitarray.BitArray) error {
+			_, err := DiffManchesterDecode(ba, 0)
+			return err
+		}, "10 0", 2, ErrLength},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			in, _ := bitarray.NewFromString(tt.in)
+			err := tt.decode(in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.Pos != tt.pos {
+				t.Errorf("got %v, want position %d", err, tt.pos)
+			}
+		})
+	}
+}
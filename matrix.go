@@ -0,0 +1,335 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+var (
+	// ErrDimension is returned for matrices and vectors of incompatible sizes.
+	ErrDimension = errors.New("incompatible dimensions")
+	// ErrSingular is returned when a matrix has no inverse or a system has no
+	// solution.
+	ErrSingular = errors.New("singular matrix")
+)
+
+// BitMatrix is a matrix over GF(2). Each row is packed MSB first, as in a
+// BitArray, into a whole number of bytes.
+type BitMatrix struct {
+	rows, cols int
+	stride     int
+	raw        []byte
+}
+
+// NewBitMatrix creates a zero matrix.
+func NewBitMatrix(rows, cols int) *BitMatrix {
+	stride := (cols + 7) / 8
+	return &BitMatrix{rows: rows, cols: cols, stride: stride, raw: make([]byte, rows*stride)}
+}
+
+// NewBitMatrixFromRows creates a matrix from rows of equal length.
+func NewBitMatrixFromRows(rows ...*BitArray) (*BitMatrix, error) {
+	cols := 0
+	if len(rows) > 0 {
+		cols = int(rows[0].Len())
+	}
+	m := NewBitMatrix(len(rows), cols)
+	for i, r := range rows {
+		if int(r.Len()) != cols {
+			return nil, fmt.Errorf("%w: row %d has %d bits, want %d", ErrDimension, i, r.Len(), cols)
+		}
+		copy(m.row(i), r.ASN1().Bytes)
+	}
+	return m, nil
+}
+
+// Identity returns the n by n identity matrix.
+func Identity(n int) *BitMatrix {
+	m := NewBitMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, true)
+	}
+	return m
+}
+
+// Rows returns the number of rows.
+func (m *BitMatrix) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns.
+func (m *BitMatrix) Cols() int {
+	return m.cols
+}
+
+// Get returns true if the bit at row r, column c is 1.
+func (m *BitMatrix) Get(r, c int) bool {
+	m.check(r, c)
+	return m.raw[r*m.stride+c/8]&(0x80>>uint(c%8)) != 0
+}
+
+// Set the bit at row r, column c.
+func (m *BitMatrix) Set(r, c int, v bool) {
+	m.check(r, c)
+	if v {
+		m.raw[r*m.stride+c/8] |= 0x80 >> uint(c%8)
+	} else {
+		m.raw[r*m.stride+c/8] &^= 0x80 >> uint(c%8)
+	}
+}
+
+// Row returns a copy of row r.
+func (m *BitMatrix) Row(r int) *BitArray {
+	m.checkRow(r)
+	return NewFromBytes(append([]byte(nil), m.row(r)...), int64(m.cols))
+}
+
+// Col returns a copy of column c.
+func (m *BitMatrix) Col(c int) *BitArray {
+	m.checkCol(c)
+	out := New()
+	for r := 0; r < m.rows; r++ {
+		if m.Get(r, c) {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+	}
+	return out
+}
+
+// Equal returns true if m and o have the same size and bits.
+func (m *BitMatrix) Equal(o *BitMatrix) bool {
+	if m.rows != o.rows || m.cols != o.cols {
+		return false
+	}
+	for i := range m.raw {
+		if m.raw[i] != o.raw[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Transpose returns the transpose of m. Full 64x64 blocks are transposed as
+// words and the remainder as 8x8 blocks of bytes.
+func (m *BitMatrix) Transpose() *BitMatrix {
+	out := NewBitMatrix(m.cols, m.rows)
+	rows64, cols64 := m.rows/64*64, m.cols/64*64
+	var block [64]uint64
+	for bi := 0; bi < rows64; bi += 64 {
+		for bj := 0; bj < cols64; bj += 64 {
+			for k := range block {
+				block[k] = binary.BigEndian.Uint64(m.raw[(bi+k)*m.stride+bj/8:])
+			}
+			transpose64(&block)
+			for k := range block {
+				binary.BigEndian.PutUint64(out.raw[(bj+k)*out.stride+bi/8:], block[k])
+			}
+		}
+	}
+	for bi := 0; bi < m.rows; bi += 8 {
+		for bj := 0; bj < m.cols; bj += 8 {
+			if bi < rows64 && bj < cols64 {
+				continue
+			}
+			var x uint64
+			for k := 0; k < 8 && bi+k < m.rows; k++ {
+				x |= uint64(m.raw[(bi+k)*m.stride+bj/8]) << uint(56-8*k)
+			}
+			x = transpose8(x)
+			for k := 0; k < 8 && bj+k < m.cols; k++ {
+				out.raw[(bj+k)*out.stride+bi/8] = byte(x >> uint(56-8*k))
+			}
+		}
+	}
+	return out
+}
+
+// Mul returns the product m*o.
+func (m *BitMatrix) Mul(o *BitMatrix) (*BitMatrix, error) {
+	if m.cols != o.rows {
+		return nil, fmt.Errorf("%w: %dx%d by %dx%d", ErrDimension, m.rows, m.cols, o.rows, o.cols)
+	}
+	out := NewBitMatrix(m.rows, o.cols)
+	for i := 0; i < m.rows; i++ {
+		dst := out.row(i)
+		for j := 0; j < m.cols; j++ {
+			if m.Get(i, j) {
+				xorBytes(dst, o.row(j))
+			}
+		}
+	}
+	return out, nil
+}
+
+// MulVec returns the product m*v for a column vector v.
+func (m *BitMatrix) MulVec(v *BitArray) (*BitArray, error) {
+	if int(v.Len()) != m.cols {
+		return nil, fmt.Errorf("%w: %dx%d by %d", ErrDimension, m.rows, m.cols, v.Len())
+	}
+	vb := v.ASN1().Bytes
+	out := New()
+	for i := 0; i < m.rows; i++ {
+		n := 0
+		for j, b := range m.row(i) {
+			n += bits.OnesCount8(b & vb[j])
+		}
+		out.AddBit(uint(n & 1))
+	}
+	return out, nil
+}
+
+// Rank returns the rank of m.
+func (m *BitMatrix) Rank() int {
+	r, _ := m.clone().eliminate(nil)
+	return r
+}
+
+// Inverse returns the inverse of a square matrix, or ErrSingular.
+func (m *BitMatrix) Inverse() (*BitMatrix, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: %dx%d is not square", ErrDimension, m.rows, m.cols)
+	}
+	out := Identity(m.rows)
+	if r, _ := m.clone().eliminate(out); r < m.rows {
+		return nil, ErrSingular
+	}
+	return out, nil
+}
+
+// Solve returns x where m*x = b. If there are many solutions, free variables
+// are zero. It returns ErrSingular if there is no solution.
+func (m *BitMatrix) Solve(b *BitArray) (*BitArray, error) {
+	if int(b.Len()) != m.rows {
+		return nil, fmt.Errorf("%w: %dx%d with %d", ErrDimension, m.rows, m.cols, b.Len())
+	}
+	aug := NewBitMatrix(m.rows, 1)
+	for i := 0; i < m.rows; i++ {
+		aug.Set(i, 0, b.Test(int64(i)))
+	}
+	a := m.clone()
+	r, pivots := a.eliminate(aug)
+	for i := r; i < m.rows; i++ {
+		if aug.Get(i, 0) {
+			return nil, ErrSingular
+		}
+	}
+	x := NewBitMatrix(1, m.cols)
+	for i, c := range pivots {
+		x.Set(0, c, aug.Get(i, 0))
+	}
+	return x.Row(0), nil
+}
+
+// String returns each row as BitArray.String, one per line.
+func (m *BitMatrix) String() string {
+	rows := make([]string, m.rows)
+	for i := range rows {
+		rows[i] = m.Row(i).String()
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Reduce m to reduced row echelon form, applying the same row operations to
+// aug if not nil. Returns the rank and the pivot column of each pivot row.
+func (m *BitMatrix) eliminate(aug *BitMatrix) (int, []int) {
+	var pivots []int
+	r := 0
+	for c := 0; c < m.cols && r < m.rows; c++ {
+		p := -1
+		for i := r; i < m.rows; i++ {
+			if m.Get(i, c) {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		m.swap(r, p)
+		if aug != nil {
+			aug.swap(r, p)
+		}
+		for i := 0; i < m.rows; i++ {
+			if i != r && m.Get(i, c) {
+				xorBytes(m.row(i), m.row(r))
+				if aug != nil {
+					xorBytes(aug.row(i), aug.row(r))
+				}
+			}
+		}
+		pivots = append(pivots, c)
+		r++
+	}
+	return r, pivots
+}
+
+func (m *BitMatrix) row(r int) []byte {
+	return m.raw[r*m.stride : (r+1)*m.stride]
+}
+
+func (m *BitMatrix) swap(a, b int) {
+	if a == b {
+		return
+	}
+	ra, rb := m.row(a), m.row(b)
+	for i := range ra {
+		ra[i], rb[i] = rb[i], ra[i]
+	}
+}
+
+func (m *BitMatrix) clone() *BitMatrix {
+	out := *m
+	out.raw = append([]byte(nil), m.raw...)
+	return &out
+}
+
+func (m *BitMatrix) check(r, c int) {
+	if r < 0 || r >= m.rows || c < 0 || c >= m.cols {
+		panic(fmt.Sprintf("bitarray: index (%d,%d) out of range %dx%d", r, c, m.rows, m.cols))
+	}
+}
+
+func (m *BitMatrix) checkRow(r int) {
+	if r < 0 || r >= m.rows {
+		panic(fmt.Sprintf("bitarray: row %d out of range %dx%d", r, m.rows, m.cols))
+	}
+}
+
+func (m *BitMatrix) checkCol(c int) {
+	if c < 0 || c >= m.cols {
+		panic(fmt.Sprintf("bitarray: column %d out of range %dx%d", c, m.rows, m.cols))
+	}
+}
+
+func xorBytes(dst, src []byte) {
+	for i, b := range src {
+		dst[i] ^= b
+	}
+}
+
+// Transpose an 8x8 block, row i in byte i from the top, MSB first.
+func transpose8(x uint64) uint64 {
+	t := (x ^ x>>7) & 0x00aa00aa00aa00aa
+	x ^= t ^ t<<7
+	t = (x ^ x>>14) & 0x0000cccc0000cccc
+	x ^= t ^ t<<14
+	t = (x ^ x>>28) & 0x00000000f0f0f0f0
+	return x ^ t ^ t<<28
+}
+
+// Transpose a 64x64 block in place, MSB first.
+func transpose64(a *[64]uint64) {
+	m := uint64(0x00000000ffffffff)
+	for j := uint(32); j != 0; j, m = j>>1, m^(m<<(j>>1)) {
+		for k := uint(0); k < 64; k = (k + j + 1) &^ j {
+			t := (a[k] ^ a[k+j]>>j) & m
+			a[k] ^= t
+			a[k+j] ^= t << j
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package bitarray
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func randMatrix(rnd *rand.Rand, rows, cols int) *BitMatrix {
+	m := NewBitMatrix(rows, cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			m.Set(r, c, rnd.Intn(2) == 1)
+		}
+	}
+	return m
+}
+
+func matrixFromStrings(rows ...string) *BitMatrix {
+	m := NewBitMatrix(len(rows), len(rows[0]))
+	for r, s := range rows {
+		for c, b := range s {
+			m.Set(r, c, b == '1')
+		}
+	}
+	return m
+}
+
+func TestBitMatrixGetSet(t *testing.T) {
+	m := NewBitMatrix(3, 10)
+	m.Set(0, 0, true)
+	m.Set(1, 9, true)
+	m.Set(2, 4, true)
+	m.Set(2, 5, true)
+	m.Set(2, 5, false)
+	expected := "[10000000 00------]\n[00000000 01------]\n[00001000 00------]"
+	if actual := m.String(); actual != expected {
+		t.Errorf("got\n%s\nwant\n%s", actual, expected)
+	}
+	if !m.Get(1, 9) || m.Get(1, 8) {
+		t.Error("got wrong bits")
+	}
+	if actual := m.Row(2).String(); actual != "[00001000 00------]" {
+		t.Errorf("got %s, want [00001000 00------]", actual)
+	}
+	if actual := m.Col(0).String(); actual != "[100-----]" {
+		t.Errorf("got %s, want [100-----]", actual)
+	}
+
+	rows, err := NewBitMatrixFromRows(m.Row(0), m.Row(1), m.Row(2))
+	if err != nil || !rows.Equal(m) {
+		t.Errorf("got %v %v, want equal", rows, err)
+	}
+	if _, err := NewBitMatrixFromRows(m.Row(0), New()); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	m.Get(3, 0)
+}
+
+func TestTranspose8(t *testing.T) {
+	// Each row has a single bit on the anti-diagonal
+	var x uint64
+	for k := 0; k < 8; k++ {
+		x |= uint64(1<<uint(k)) << uint(56-8*k)
+	}
+	if actual := transpose8(x); actual != x {
+		t.Errorf("got %#016x, want %#016x", actual, x)
+	}
+	if actual := transpose8(0xff00000000000000); actual != 0x8080808080808080 {
+		t.Errorf("got %#016x, want 0x8080808080808080", actual)
+	}
+}
+
+func TestBitMatrixTranspose(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	sizes := [][2]int{{1, 1}, {3, 10}, {8, 8}, {64, 64}, {65, 130}, {130, 64}, {200, 77}, {0, 5}}
+	for _, size := range sizes {
+		m := randMatrix(rnd, size[0], size[1])
+		tr := m.Transpose()
+		if tr.Rows() != size[1] || tr.Cols() != size[0] {
+			t.Fatalf("%v: got %dx%d", size, tr.Rows(), tr.Cols())
+		}
+		for r := 0; r < size[0]; r++ {
+			for c := 0; c < size[1]; c++ {
+				if m.Get(r, c) != tr.Get(c, r) {
+					t.Fatalf("%v: got wrong bit at %d,%d", size, r, c)
+				}
+			}
+		}
+		if !tr.Transpose().Equal(m) {
+			t.Errorf("%v: expected double transpose to be equal", size)
+		}
+	}
+}
+
+func TestBitMatrixMul(t *testing.T) {
+	a := matrixFromStrings("101", "011")
+	b := matrixFromStrings("11", "01", "10")
+	expected := matrixFromStrings("01", "11")
+	actual, err := a.Mul(b)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if !actual.Equal(expected) {
+		t.Errorf("got\n%s\nwant\n%s", actual, expected)
+	}
+	if _, err := a.Mul(a); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+
+	// Hamming(7,4) parity check of a codeword is zero
+	h := matrixFromStrings("1010101", "0110011", "0001111")
+	code := NewFromBytes([]byte{0x66}, 7)
+	s, err := h.MulVec(code)
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := s.String(); actual != "[000-----]" {
+		t.Errorf("got %s, want [000-----]", actual)
+	}
+	code.Set(4)
+	s, _ = h.MulVec(code)
+	// Syndrome is position 5
+	if actual := s.String(); actual != "[101-----]" {
+		t.Errorf("got %s, want [101-----]", actual)
+	}
+	if _, err := h.MulVec(New()); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestBitMatrixRank(t *testing.T) {
+	tests := map[string]struct {
+		m        *BitMatrix
+		expected int
+	}{
+		"identity":  {Identity(5), 5},
+		"zero":      {NewBitMatrix(3, 4), 0},
+		"dependent": {matrixFromStrings("110", "011", "101"), 2},
+		"wide":      {matrixFromStrings("1010101", "0110011", "0001111"), 3},
+		"tall":      {matrixFromStrings("10", "01", "11", "00"), 2},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := tt.m.Rank(); actual != tt.expected {
+				t.Errorf("got %d, want %d", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBitMatrixInverse(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	found := 0
+	for i := 0; i < 20; i++ {
+		m := randMatrix(rnd, 70, 70)
+		inv, err := m.Inverse()
+		if m.Rank() < 70 {
+			if !errors.Is(err, ErrSingular) {
+				t.Errorf("got %v, want %v", err, ErrSingular)
+			}
+			continue
+		}
+		found++
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		if p, _ := m.Mul(inv); !p.Equal(Identity(70)) {
+			t.Error("expected m*inverse to be identity")
+		}
+		if m.Rank() != 70 {
+			t.Error("expected matrix to be unchanged")
+		}
+	}
+	if found == 0 {
+		t.Error("no invertible matrices")
+	}
+	if _, err := NewBitMatrix(2, 3).Inverse(); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestBitMatrixSolve(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		m := randMatrix(rnd, 30, 40)
+		x := randMatrix(rnd, 1, 40).Row(0)
+		b, _ := m.MulVec(x)
+		actual, err := m.Solve(b)
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		if check, _ := m.MulVec(actual); check.String() != b.String() {
+			t.Errorf("got m*x = %s, want %s", check, b)
+		}
+	}
+
+	m := matrixFromStrings("110", "011", "101")
+	if _, err := m.Solve(NewFromBytes([]byte{0x20}, 3)); !errors.Is(err, ErrSingular) {
+		t.Errorf("got %v, want %v", err, ErrSingular)
+	}
+	if _, err := m.Solve(New()); !errors.Is(err, ErrDimension) {
+		t.Errorf("got %v, want %v", err, ErrDimension)
+	}
+}
+
+func TestBitMatrixZeroCols(t *testing.T) {
+	m, err := NewBitMatrixFromRows(New(), New())
+	if err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if actual := m.String(); actual != "[]\n[]" {
+		t.Errorf("got %q, want %q", actual, "[]\n[]")
+	}
+	if actual := m.Row(1).Len(); actual != 0 {
+		t.Errorf("got len=%d, want 0", actual)
+	}
+	x, err := m.Solve(NewFromBytes([]byte{0}, 2))
+	if err != nil || x.Len() != 0 {
+		t.Errorf("got %v %v, want empty", x, err)
+	}
+	if _, err := m.Solve(NewFromBytes([]byte{0x40}, 2)); !errors.Is(err, ErrSingular) {
+		t.Errorf("got %v, want %v", err, ErrSingular)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	m.Col(0)
+}
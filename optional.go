@@ -0,0 +1,135 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OptionalSet is a presence bitmap for a fixed number of optional fields.
+type OptionalSet struct {
+	bits *BitArray
+}
+
+// NewOptionalSet creates an OptionalSet for n fields, all absent.
+func NewOptionalSet(n int) *OptionalSet {
+	return &OptionalSet{bits: New(SetSize(int64(n)))}
+}
+
+// Len returns the number of fields in the set.
+func (o *OptionalSet) Len() int {
+	return int(o.bits.Len())
+}
+
+// SetPresent marks field i as present or absent.
+func (o *OptionalSet) SetPresent(i int, present bool) {
+	if i < 0 || i >= o.Len() {
+		return
+	}
+	if present {
+		o.bits.Set(int64(i))
+	} else {
+		o.bits.Unset(int64(i))
+	}
+}
+
+// IsPresent returns true if field i is present.
+func (o *OptionalSet) IsPresent(i int) bool {
+	if i < 0 {
+		return false
+	}
+	return o.bits.Test(int64(i))
+}
+
+// Encode adds the presence bitmap to ba, one bit per field.
+// Returns the number of bits added.
+func (o *OptionalSet) Encode(ba *BitArray) int {
+	ba.Append(*o.bits)
+	return o.Len()
+}
+
+// Decode reads the presence bitmap from r. It returns EOF if the bitmap is
+// truncated.
+func (o *OptionalSet) Decode(r *Reader) error {
+	out := New()
+	for n := o.Len(); n > 0; {
+		w := n
+		if w > 8 {
+			w = 8
+		}
+		var u uint
+		if err := r.ReadBits(&u, w); err != nil {
+			return err
+		}
+		out.AddN(u, w)
+		n -= w
+	}
+	o.bits = out
+	return nil
+}
+
+// ChoiceEncoder encodes the value of a Choice alternative.
+type ChoiceEncoder func(ba *BitArray, v interface{}) error
+
+// ChoiceDecoder decodes the value of a Choice alternative.
+type ChoiceDecoder func(r *Reader) (interface{}, error)
+
+type choiceAlt struct {
+	enc ChoiceEncoder
+	dec ChoiceDecoder
+}
+
+// Choice encodes one of a number of alternatives as an index of minimal width
+// followed by the value of the alternative.
+// All alternatives must be registered before encoding or decoding.
+type Choice struct {
+	alts []choiceAlt
+}
+
+// Register adds an alternative and returns its index. Either function may be
+// nil for alternatives without a value.
+func (c *Choice) Register(enc ChoiceEncoder, dec ChoiceDecoder) int {
+	c.alts = append(c.alts, choiceAlt{enc: enc, dec: dec})
+	return len(c.alts) - 1
+}
+
+// Len returns the number of alternatives.
+func (c *Choice) Len() int {
+	return len(c.alts)
+}
+
+// Width returns the number of bits used for the alternative index.
+func (c *Choice) Width() int {
+	return RangeWidth(0, int64(len(c.alts)-1))
+}
+
+// Encode adds the index alt followed by v encoded by that alternative.
+func (c *Choice) Encode(ba *BitArray, alt int, v interface{}) error {
+	if len(c.alts) == 0 {
+		return errors.New("choice has no alternatives")
+	}
+	if _, err := ba.AddRange(int64(alt), 0, int64(len(c.alts)-1)); err != nil {
+		return fmt.Errorf("choice alternative: %w", err)
+	}
+	if enc := c.alts[alt].enc; enc != nil {
+		return enc(ba, v)
+	}
+	return nil
+}
+
+// Decode reads an alternative index and its value.
+func (c *Choice) Decode(r *Reader) (int, interface{}, error) {
+	if len(c.alts) == 0 {
+		return 0, nil, errors.New("choice has no alternatives")
+	}
+	pos := r.Pos()
+	alt, err := r.ReadRange(0, int64(len(c.alts)-1))
+	if err != nil {
+		return 0, nil, fmt.Errorf("choice alternative at bit %d: %w", pos, err)
+	}
+	dec := c.alts[alt].dec
+	if dec == nil {
+		return int(alt), nil, nil
+	}
+	v, err := dec(r)
+	return int(alt), v, err
+}
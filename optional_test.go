@@ -0,0 +1,110 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptionalSet(t *testing.T) {
+	o := NewOptionalSet(10)
+	o.SetPresent(0, true)
+	o.SetPresent(3, true)
+	o.SetPresent(9, true)
+	o.SetPresent(3, false)
+	o.SetPresent(10, true)
+
+	ba := New()
+	ba.AddBit(0)
+	if n := o.Encode(ba); n != 10 {
+		t.Errorf("got n=%d, want 10", n)
+	}
+	if expected := "[01000000 001-----]"; ba.String() != expected {
+		t.Errorf("got %s, want %s", ba.String(), expected)
+	}
+
+	r := NewReader(ba)
+	r.ReadBit()
+	actual := NewOptionalSet(10)
+	if err := actual.Decode(r); err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	for i := 0; i < 11; i++ {
+		expected := i == 0 || i == 9
+		if actual.IsPresent(i) != expected {
+			t.Errorf("field %d: got %t, want %t", i, actual.IsPresent(i), expected)
+		}
+	}
+
+	if err := NewOptionalSet(12).Decode(NewReader(ba)); err != EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+	// The variable length limit does not apply to a fixed size bitmap
+	r = NewReader(ba)
+	r.SetLimit(4)
+	if err := NewOptionalSet(11).Decode(r); err != nil {
+		t.Errorf("failed with %q", err)
+	}
+}
+
+func TestChoice(t *testing.T) {
+	var c Choice
+	flag := c.Register(nil, nil)
+	small := c.Register(
+		func(ba *BitArray, v interface{}) error {
+			_, err := ba.AddRange(int64(v.(int)), 0, 15)
+			return err
+		},
+		func(r *Reader) (interface{}, error) {
+			v, err := r.ReadRange(0, 15)
+			return int(v), err
+		},
+	)
+	text := c.Register(
+		func(ba *BitArray, v interface{}) error {
+			_, err := ba.AddPrefixedBytes([]byte(v.(string)), 4)
+			return err
+		},
+		func(r *Reader) (interface{}, error) {
+			b, err := r.ReadPrefixed(4, 8)
+			if err != nil {
+				return nil, err
+			}
+			return string(b.Bytes()), nil
+		},
+	)
+	if c.Width() != 2 {
+		t.Errorf("got width=%d, want 2", c.Width())
+	}
+
+	ba := New()
+	inputs := []struct {
+		alt int
+		v   interface{}
+	}{{small, 9}, {flag, nil}, {text, "A"}}
+	for _, in := range inputs {
+		if err := c.Encode(ba, in.alt, in.v); err != nil {
+			t.Fatalf("failed to encode: %s", err)
+		}
+	}
+	if expected := "[01100100 10000101 000001--]"; ba.String() != expected {
+		t.Errorf("got %s, want %s", ba.String(), expected)
+	}
+
+	r := NewReader(ba)
+	for _, in := range inputs {
+		alt, v, err := c.Decode(r)
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+		if alt != in.alt || v != in.v {
+			t.Errorf("got %d %v, want %d %v", alt, v, in.alt, in.v)
+		}
+	}
+
+	if err := c.Encode(ba, 3, nil); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+	if _, _, err := c.Decode(NewReader(NewFromBytes([]byte{0xc0}, 2))); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+}
@@ -0,0 +1,125 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrOverflow is returned when a value does not fit the available width.
+var ErrOverflow = errors.New("value overflows width")
+
+// Overflow is the policy applied when a value needs more bits than are
+// available to store it.
+type Overflow int
+
+const (
+	// OverflowTruncateHigh keeps the most significant bits of the value.
+	// This is the default.
+	OverflowTruncateHigh Overflow = iota
+	// OverflowError rejects the value with ErrOverflow.
+	OverflowError
+	// OverflowTruncateLow keeps the least significant bits of the value.
+	OverflowTruncateLow
+	// OverflowSaturate replaces the value with the largest that fits.
+	OverflowSaturate
+)
+
+func (o Overflow) String() string {
+	switch o {
+	case OverflowTruncateHigh:
+		return "truncate-high"
+	case OverflowError:
+		return "error"
+	case OverflowTruncateLow:
+		return "truncate-low"
+	case OverflowSaturate:
+		return "saturate"
+	default:
+		return fmt.Sprintf("Overflow(%d)", int(o))
+	}
+}
+
+// SetOverflow configures the overflow policy used by AddN and Pack.
+// Pack adds integers without a fixed width, so the policy only applies to
+// negative values and to uint64 values wider than a uint.
+func SetOverflow(o Overflow) Option {
+	return func(ba *BitArray) {
+		ba.overflow = o
+	}
+}
+
+// Err returns the first error recorded by AddN under OverflowError, or nil.
+// Values after a rejected one are misaligned, so check Err once all fields
+// have been added.
+func (ba BitArray) Err() error {
+	return ba.err
+}
+
+// AddNPolicy adds a uint with a fixed width of n, left padded to width with
+// zeros, applying policy p if u needs more than width bits.
+// Returns the number of bits added.
+func (ba *BitArray) AddNPolicy(u uint, width int, p Overflow) (int, error) {
+	n := bits.Len(u)
+	if n > width {
+		switch p {
+		case OverflowTruncateHigh:
+			u >>= uint(n - width)
+		case OverflowError:
+			return 0, fmt.Errorf("%w: %d needs %d bits, have %d", ErrOverflow, u, n, width)
+		case OverflowTruncateLow:
+			u &= maxUint(width)
+		case OverflowSaturate:
+			u = maxUint(width)
+		default:
+			return 0, fmt.Errorf("invalid overflow policy %d", p)
+		}
+		n = bits.Len(u)
+	}
+	c := ba.Pad(uint(width - n))
+	if n != 0 {
+		c += ba.Add(u)
+	}
+	return c, nil
+}
+
+// The largest value that fits in width bits.
+func maxUint(width int) uint {
+	if width >= bits.UintSize {
+		return ^uint(0)
+	}
+	return 1<<uint(width) - 1
+}
+
+// Convert a signed value for packing, applying policy p to negative values.
+func packInt(i int64, p Overflow) (uint, error) {
+	if i >= 0 {
+		return uint(i), nil
+	}
+	switch p {
+	case OverflowError:
+		return 0, fmt.Errorf("%w: negative value %d", ErrOverflow, i)
+	case OverflowSaturate:
+		return 0, nil
+	default:
+		return uint(i), nil
+	}
+}
+
+// Convert an unsigned 64 bit value for packing, applying policy p when it
+// does not fit in a uint.
+func packUint64(u uint64, p Overflow) (uint, error) {
+	if bits.Len64(u) <= bits.UintSize {
+		return uint(u), nil
+	}
+	switch p {
+	case OverflowTruncateHigh:
+		return uint(u >> uint(64-bits.UintSize)), nil
+	case OverflowError:
+		return 0, fmt.Errorf("%w: %d needs %d bits, have %d", ErrOverflow, u, bits.Len64(u), bits.UintSize)
+	case OverflowSaturate:
+		return ^uint(0), nil
+	default:
+		return uint(u), nil
+	}
+}
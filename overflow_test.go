@@ -0,0 +1,92 @@
+package bitarray
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddNPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in       uint
+		width    int
+		policy   Overflow
+		expected string
+		err      error
+	}{
+		"fits":          {0x0f, 8, OverflowError, "[00001111]", nil},
+		"truncateHigh":  {0x1ff, 8, OverflowTruncateHigh, "[11111111]", nil},
+		"truncateHigh2": {0x1f0, 4, OverflowTruncateHigh, "[1111----]", nil},
+		"error":         {0x1ff, 8, OverflowError, "[]", ErrOverflow},
+		"truncateLow":   {0x1f0, 8, OverflowTruncateLow, "[11110000]", nil},
+		"truncateLow0":  {0x100, 4, OverflowTruncateLow, "[0000----]", nil},
+		"saturate":      {0x100, 4, OverflowSaturate, "[1111----]", nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			n, err := ba.AddNPolicy(tt.in, tt.width, tt.policy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			actual := ba.String()
+			if actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			if int64(n) != ba.Len() {
+				t.Errorf("got n=%d, want %d", n, ba.Len())
+			}
+		})
+	}
+}
+
+func TestAddNOption(t *testing.T) {
+	ba := New(SetOverflow(OverflowTruncateLow))
+	if n := ba.AddN(0x1ff, 8); n != 8 {
+		t.Errorf("got n=%d, want 8", n)
+	}
+	if ba.Err() != nil {
+		t.Errorf("got %v, want nil", ba.Err())
+	}
+	ba = New(SetOverflow(OverflowError))
+	if n := ba.AddN(0x1ff, 8); n != 0 {
+		t.Errorf("got n=%d, want 0", n)
+	}
+	if ba.Len() != 0 {
+		t.Errorf("got len=%d, want 0", ba.Len())
+	}
+	ba.AddN(0x3ff, 8)
+	if err := ba.Err(); !errors.Is(err, ErrOverflow) || !strings.Contains(err.Error(), "511") {
+		t.Errorf("got %v, want first %v", err, ErrOverflow)
+	}
+}
+
+func TestPackPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in       interface{}
+		policy   Overflow
+		expected string
+		err      error
+	}{
+		"positive":      {int8(5), OverflowError, "[101-----]", nil},
+		"negativeError": {int8(-1), OverflowError, "[]", ErrOverflow},
+		"negativeSlice": {[]int{1, -1}, OverflowError, "[1-------]", ErrOverflow},
+		"saturate":      {[]int16{-5, 1}, OverflowSaturate, "[01------]", nil},
+		"nested":        {[]interface{}{[]interface{}{1}, 2}, OverflowError, "[110-----]", nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			err := ba.PackPolicy(tt.policy, tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			actual := ba.String()
+			if actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+}
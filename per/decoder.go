@@ -0,0 +1,349 @@
+package per
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// Decoder reads UPER encodings from a Reader.
+type Decoder struct {
+	r *bitarray.Reader
+}
+
+// NewDecoder creates a Decoder reading from r.
+func NewDecoder(r *bitarray.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Reader returns the underlying Reader.
+func (d *Decoder) Reader() *bitarray.Reader {
+	return d.r
+}
+
+func (d *Decoder) bits(n int) (uint, error) {
+	var u uint
+	if n == 0 {
+		return 0, nil
+	}
+	err := d.r.ReadBits(&u, n)
+	return u, err
+}
+
+// Bool decodes a BOOLEAN.
+func (d *Decoder) Bool() (bool, error) {
+	u, err := d.bits(1)
+	return u == 1, err
+}
+
+// Integer decodes an INTEGER with the value constraint r.
+func (d *Decoder) Integer(r *Range) (int64, error) {
+	if r == nil {
+		return d.unconstrained()
+	}
+	if r.Lower > r.Upper {
+		return 0, fmt.Errorf("invalid range %d..%d", r.Lower, r.Upper)
+	}
+	if r.Extensible {
+		ext, err := d.Bool()
+		if err != nil {
+			return 0, err
+		}
+		if ext {
+			return d.unconstrained()
+		}
+	}
+	return d.r.ReadRange(r.Lower, r.Upper)
+}
+
+// SemiConstrained decodes an INTEGER with only a lower bound.
+func (d *Decoder) SemiConstrained(lower int64) (int64, error) {
+	n, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > 8 {
+		return 0, fmt.Errorf("%w: %d octet integer", ErrUnsupported, n)
+	}
+	u, err := d.bits(n * 8)
+	if err != nil {
+		return 0, err
+	}
+	v := int64(uint64(lower) + uint64(u))
+	if uint64(u) > math.MaxInt64 || v < lower {
+		return 0, fmt.Errorf("%w: %d above %d does not fit 64 bits", bitarray.ErrOutOfRange, u, lower)
+	}
+	return v, nil
+}
+
+func (d *Decoder) unconstrained() (int64, error) {
+	n, err := d.length()
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > 8 {
+		return 0, fmt.Errorf("%w: %d octet integer", ErrUnsupported, n)
+	}
+	u, err := d.bits(n * 8)
+	if err != nil {
+		return 0, err
+	}
+	shift := uint(64 - n*8)
+	return int64(uint64(u)<<shift) >> shift, nil
+}
+
+// Enumerated decodes the index of an ENUMERATED with count root values.
+// Extension values are returned as indexes from count up.
+func (d *Decoder) Enumerated(count int, extensible bool) (int, error) {
+	return d.index(count, extensible)
+}
+
+// Choice decodes the index of a CHOICE with count root alternatives.
+// Extension additions are returned as indexes from count up and their value
+// must be read with OpenType.
+func (d *Decoder) Choice(count int, extensible bool) (int, error) {
+	return d.index(count, extensible)
+}
+
+func (d *Decoder) index(count int, extensible bool) (int, error) {
+	if extensible {
+		ext, err := d.Bool()
+		if err != nil {
+			return 0, err
+		}
+		if ext {
+			n, err := d.normallySmall()
+			return count + n, err
+		}
+	}
+	i, err := d.r.ReadRange(0, int64(count-1))
+	return int(i), err
+}
+
+// Decode a normally small non-negative whole number.
+func (d *Decoder) normallySmall() (int, error) {
+	large, err := d.Bool()
+	if err != nil {
+		return 0, err
+	}
+	if !large {
+		u, err := d.bits(6)
+		return int(u), err
+	}
+	v, err := d.SemiConstrained(0)
+	if err == nil && v > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: %d", bitarray.ErrOutOfRange, v)
+	}
+	return int(v), err
+}
+
+// Decode a normally small length.
+func (d *Decoder) normallySmallLength() (int, error) {
+	large, err := d.Bool()
+	if err != nil {
+		return 0, err
+	}
+	if !large {
+		u, err := d.bits(6)
+		return int(u) + 1, err
+	}
+	n, err := d.length()
+	if err == nil && n < 1 {
+		return 0, fmt.Errorf("%w: length %d", bitarray.ErrOutOfRange, n)
+	}
+	return n, err
+}
+
+// Sequence decodes the preamble of a SEQUENCE with the given number of
+// OPTIONAL and DEFAULT components. It returns their presence and whether
+// extension additions follow the root components, to be skipped with
+// SkipExtensions.
+func (d *Decoder) Sequence(extensible bool, optional int) (*bitarray.OptionalSet, bool, error) {
+	var ext bool
+	if extensible {
+		var err error
+		if ext, err = d.Bool(); err != nil {
+			return nil, false, err
+		}
+	}
+	set := bitarray.NewOptionalSet(optional)
+	if err := set.Decode(d.r); err != nil {
+		return nil, false, err
+	}
+	return set, ext, nil
+}
+
+// Extensions decodes the extension additions of a SEQUENCE. It returns the
+// open type octets of each addition, nil for those that are absent.
+func (d *Decoder) Extensions() ([][]byte, error) {
+	n, err := d.normallySmallLength()
+	if err != nil {
+		return nil, err
+	}
+	present := bitarray.NewOptionalSet(n)
+	if err := present.Decode(d.r); err != nil {
+		return nil, err
+	}
+	out := make([][]byte, n)
+	for i := range out {
+		if !present.IsPresent(i) {
+			continue
+		}
+		if out[i], err = d.OpenType(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// SkipExtensions skips the extension additions of a SEQUENCE.
+func (d *Decoder) SkipExtensions() error {
+	_, err := d.Extensions()
+	return err
+}
+
+// SequenceOf decodes the number of components of a SEQUENCE OF.
+func (d *Decoder) SequenceOf(s *Size) (int, error) {
+	return d.sizedLength(s)
+}
+
+// BitString decodes a BIT STRING.
+func (d *Decoder) BitString(s *Size) (*bitarray.BitArray, error) {
+	n, err := d.sizedLength(s)
+	if err != nil {
+		return nil, err
+	}
+	out := bitarray.New()
+	for n > 0 {
+		w := n
+		if w > 8 {
+			w = 8
+		}
+		u, err := d.bits(w)
+		if err != nil {
+			return nil, err
+		}
+		out.AddN(u, w)
+		n -= w
+	}
+	return out, nil
+}
+
+// OctetString decodes an OCTET STRING.
+func (d *Decoder) OctetString(s *Size) ([]byte, error) {
+	n, err := d.sizedLength(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, n)
+	for i := range out {
+		u, err := d.bits(8)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = byte(u)
+	}
+	return out, nil
+}
+
+// IA5String decodes an IA5String.
+func (d *Decoder) IA5String(s *Size) (string, error) {
+	return d.KnownMultiplierString(ia5Alphabet, s)
+}
+
+// VisibleString decodes a VisibleString.
+func (d *Decoder) VisibleString(s *Size) (string, error) {
+	return d.KnownMultiplierString(visibleAlphabet, s)
+}
+
+// NumericString decodes a NumericString.
+func (d *Decoder) NumericString(s *Size) (string, error) {
+	return d.KnownMultiplierString(NumericAlphabet, s)
+}
+
+// PrintableString decodes a PrintableString.
+func (d *Decoder) PrintableString(s *Size) (string, error) {
+	return d.KnownMultiplierString(PrintableAlphabet, s)
+}
+
+// KnownMultiplierString decodes a string of single byte characters from the
+// permitted alphabet, given in ascending order.
+func (d *Decoder) KnownMultiplierString(alphabet string, s *Size) (string, error) {
+	n, err := d.sizedLength(s)
+	if err != nil {
+		return "", err
+	}
+	width, indexed := charEncoding(alphabet)
+	var out strings.Builder
+	for i := 0; i < n; i++ {
+		pos := d.r.Pos()
+		u, err := d.bits(width)
+		if err != nil {
+			return "", err
+		}
+		if indexed {
+			if int(u) >= len(alphabet) {
+				return "", fmt.Errorf("%w: character index %d at bit %d", bitarray.ErrOutOfRange, u, pos)
+			}
+			out.WriteByte(alphabet[u])
+			continue
+		}
+		if strings.IndexByte(alphabet, byte(u)) < 0 {
+			return "", fmt.Errorf("%w: character %q at bit %d", bitarray.ErrOutOfRange, byte(u), pos)
+		}
+		out.WriteByte(byte(u))
+	}
+	return out.String(), nil
+}
+
+// OpenType decodes the octets of an open type.
+func (d *Decoder) OpenType() ([]byte, error) {
+	return d.OctetString(nil)
+}
+
+// Decode a length with an optional size constraint.
+func (d *Decoder) sizedLength(s *Size) (int, error) {
+	if s == nil {
+		return d.length()
+	}
+	if s.Min > s.Max || s.Min < 0 {
+		return 0, fmt.Errorf("invalid size %d..%d", s.Min, s.Max)
+	}
+	if s.Extensible {
+		ext, err := d.Bool()
+		if err != nil {
+			return 0, err
+		}
+		if ext {
+			return d.length()
+		}
+	}
+	if s.Max >= maxConstrainedLength {
+		return d.length()
+	}
+	n, err := d.r.ReadRange(int64(s.Min), int64(s.Max))
+	return int(n), err
+}
+
+// Decode an unconstrained length determinant.
+func (d *Decoder) length() (int, error) {
+	long, err := d.Bool()
+	if err != nil {
+		return 0, err
+	}
+	if !long {
+		u, err := d.bits(7)
+		return int(u), err
+	}
+	frag, err := d.Bool()
+	if err != nil {
+		return 0, err
+	}
+	if frag {
+		return 0, fmt.Errorf("%w: fragmented length", ErrUnsupported)
+	}
+	u, err := d.bits(14)
+	return int(u), err
+}
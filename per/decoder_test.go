@@ -0,0 +1,466 @@
+package per
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// A record with every supported type:
+//
+//	Record ::= SEQUENCE {
+//		id      INTEGER (0..1023),
+//		count   INTEGER,
+//		age     INTEGER (0..MAX) OPTIONAL,
+//		kind    ENUMERATED { a, b, c, ... },
+//		flags   BIT STRING (SIZE (6)),
+//		data    OCTET STRING (SIZE (0..15)),
+//		name    IA5String,
+//		code    NumericString (SIZE (1..8)),
+//		values  SEQUENCE (SIZE (0..3)) OF BOOLEAN,
+//		choice  CHOICE { n INTEGER (0..15), s PrintableString, ... },
+//		...
+//	}
+type record struct {
+	id     int64
+	count  int64
+	age    *int64
+	kind   int
+	flags  string
+	data   []byte
+	name   string
+	code   string
+	values []bool
+	alt    int
+	n      int64
+	s      string
+}
+
+var (
+	idRange = &Range{Lower: 0, Upper: 1023}
+	nRange  = &Range{Lower: 0, Upper: 15}
+	dataLen = &Size{Min: 0, Max: 15}
+	codeLen = &Size{Min: 1, Max: 8}
+	valLen  = &Size{Min: 0, Max: 3}
+	flagLen = &Size{Min: 6, Max: 6}
+)
+
+func (r record) encode(e *Encoder) error {
+	opt := bitarray.NewOptionalSet(1)
+	opt.SetPresent(0, r.age != nil)
+	e.Sequence(true, opt)
+	if err := e.Integer(r.id, idRange); err != nil {
+		return err
+	}
+	if err := e.Integer(r.count, nil); err != nil {
+		return err
+	}
+	if r.age != nil {
+		if err := e.SemiConstrained(*r.age, 0); err != nil {
+			return err
+		}
+	}
+	if err := e.Enumerated(r.kind, 3, true); err != nil {
+		return err
+	}
+	flags := bitarray.New()
+	for _, c := range r.flags {
+		if c == '1' {
+			flags.AddBit(1)
+		} else {
+			flags.AddBit(0)
+		}
+	}
+	if err := e.BitString(flags, flagLen); err != nil {
+		return err
+	}
+	if err := e.OctetString(r.data, dataLen); err != nil {
+		return err
+	}
+	if err := e.IA5String(r.name, nil); err != nil {
+		return err
+	}
+	if err := e.NumericString(r.code, codeLen); err != nil {
+		return err
+	}
+	if err := e.SequenceOf(len(r.values), valLen); err != nil {
+		return err
+	}
+	for _, v := range r.values {
+		e.Bool(v)
+	}
+	if err := e.Choice(r.alt, 2, true); err != nil {
+		return err
+	}
+	if r.alt == 0 {
+		return e.Integer(r.n, nRange)
+	}
+	return e.PrintableString(r.s, nil)
+}
+
+func decodeRecord(d *Decoder) (record, error) {
+	var r record
+	opt, ext, err := d.Sequence(true, 1)
+	if err != nil {
+		return r, err
+	}
+	if r.id, err = d.Integer(idRange); err != nil {
+		return r, err
+	}
+	if r.count, err = d.Integer(nil); err != nil {
+		return r, err
+	}
+	if opt.IsPresent(0) {
+		age, err := d.SemiConstrained(0)
+		if err != nil {
+			return r, err
+		}
+		r.age = &age
+	}
+	if r.kind, err = d.Enumerated(3, true); err != nil {
+		return r, err
+	}
+	flags, err := d.BitString(flagLen)
+	if err != nil {
+		return r, err
+	}
+	for i := int64(0); i < flags.Len(); i++ {
+		if flags.Test(i) {
+			r.flags += "1"
+		} else {
+			r.flags += "0"
+		}
+	}
+	if r.data, err = d.OctetString(dataLen); err != nil {
+		return r, err
+	}
+	if r.name, err = d.IA5String(nil); err != nil {
+		return r, err
+	}
+	if r.code, err = d.NumericString(codeLen); err != nil {
+		return r, err
+	}
+	n, err := d.SequenceOf(valLen)
+	if err != nil {
+		return r, err
+	}
+	for i := 0; i < n; i++ {
+		v, err := d.Bool()
+		if err != nil {
+			return r, err
+		}
+		r.values = append(r.values, v)
+	}
+	if r.alt, err = d.Choice(2, true); err != nil {
+		return r, err
+	}
+	if r.alt == 0 {
+		r.n, err = d.Integer(nRange)
+	} else {
+		r.s, err = d.PrintableString(nil)
+	}
+	if err == nil && ext {
+		err = d.SkipExtensions()
+	}
+	return r, err
+}
+
+func TestRoundTrip(t *testing.T) {
+	age := int64(42)
+	tests := map[string]record{
+		"full": {
+			id: 1000, count: -70000, age: &age, kind: 2, flags: "101101",
+			data: []byte{0xde, 0xad}, name: "Smith", code: "0123 9",
+			values: []bool{true, false, true}, alt: 1, s: "Hello, World.",
+		},
+		"minimal": {
+			id: 0, count: 0, kind: 5, flags: "000000", data: []byte{},
+			name: "", code: "7", alt: 0, n: 15,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncoder(nil)
+			if err := tt.encode(e); err != nil {
+				t.Fatalf("failed to encode: %s", err)
+			}
+			r := bitarray.NewReader(e.BitArray())
+			actual, err := decodeRecord(NewDecoder(r))
+			if err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			if !reflect.DeepEqual(actual, tt) {
+				t.Errorf("got %+v, want %+v", actual, tt)
+			}
+			if r.Pos() != e.BitArray().Len() {
+				t.Errorf("got pos=%d, want %d", r.Pos(), e.BitArray().Len())
+			}
+		})
+	}
+}
+
+func TestSkipExtensions(t *testing.T) {
+	// SEQUENCE { a BOOLEAN, ..., b INTEGER, c BOOLEAN } with b and c present
+	ba := bitarray.New()
+	ba.AddBit(1)             // extension bit
+	ba.AddBit(1)             // a
+	ba.AddN(1, 7)            // two extension additions
+	ba.AddN(3, 2)            // both present
+	ba.Pack([]byte{1, 0x05}) // b as an open type
+	ba.Pack([]byte{1, 0x80}) // c as an open type
+	ba.AddBit(1)             // trailing data
+
+	r := bitarray.NewReader(ba)
+	d := NewDecoder(r)
+	_, ext, err := d.Sequence(true, 0)
+	if err != nil || !ext {
+		t.Fatalf("got ext=%t %v, want true", ext, err)
+	}
+	if a, _ := d.Bool(); !a {
+		t.Error("got false, want true")
+	}
+	if err := d.SkipExtensions(); err != nil {
+		t.Fatalf("failed to skip: %s", err)
+	}
+	if r.Pos() != ba.Len()-1 {
+		t.Errorf("got pos=%d, want %d", r.Pos(), ba.Len()-1)
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	// More than 64 additions use the long form of the bitmap length
+	additions := make([]func(*Encoder) error, 70)
+	additions[0] = func(e *Encoder) error { e.Bool(true); return nil }
+	additions[69] = func(e *Encoder) error { return e.Integer(5, &Range{Lower: 0, Upper: 255}) }
+	e := NewEncoder(nil)
+	e.ExtendedSequence(nil)
+	if err := e.Extensions(additions...); err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+
+	r := bitarray.NewReader(e.BitArray())
+	d := NewDecoder(r)
+	if _, ext, err := d.Sequence(true, 0); err != nil || !ext {
+		t.Fatalf("got ext=%t %v, want true", ext, err)
+	}
+	actual, err := d.Extensions()
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if len(actual) != 70 {
+		t.Fatalf("got %d additions, want 70", len(actual))
+	}
+	if !reflect.DeepEqual(actual[0], []byte{0x80}) || !reflect.DeepEqual(actual[69], []byte{0x05}) {
+		t.Errorf("got %x and %x, want 80 and 05", actual[0], actual[69])
+	}
+	for i := 1; i < 69; i++ {
+		if actual[i] != nil {
+			t.Errorf("got addition %d present", i)
+		}
+	}
+	if r.Pos() != e.BitArray().Len() {
+		t.Errorf("got pos=%d, want %d", r.Pos(), e.BitArray().Len())
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := map[string]struct {
+		in     []byte
+		n      int64
+		decode func(d *Decoder) error
+		err    error
+	}{
+		"rangeCode": {[]byte{0xe0}, 3, func(d *Decoder) error {
+			_, err := d.Integer(&Range{Lower: 0, Upper: 4})
+			return err
+		}, bitarray.ErrOutOfRange},
+		"numericIndex": {[]byte{0x01, 0xf0}, 12, func(d *Decoder) error {
+			_, err := d.NumericString(nil)
+			return err
+		}, bitarray.ErrOutOfRange},
+		"fragmented": {[]byte{0xc0}, 8, func(d *Decoder) error {
+			_, err := d.OctetString(nil)
+			return err
+		}, ErrUnsupported},
+		"wideInteger": {[]byte{0x09}, 8, func(d *Decoder) error {
+			_, err := d.Integer(nil)
+			return err
+		}, ErrUnsupported},
+		"short": {[]byte{0x02, 0x41}, 16, func(d *Decoder) error {
+			_, err := d.OctetString(nil)
+			return err
+		}, bitarray.EOF},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewDecoder(bitarray.NewReader(bitarray.NewFromBytes(tt.in, tt.n)))
+			if err := tt.decode(d); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// The PersonnelRecord of X.691 Annex A.1:
+//
+//	PersonnelRecord ::= [APPLICATION 0] IMPLICIT SET {
+//		name         Name,
+//		title        [0] VisibleString,
+//		number       EmployeeNumber,
+//		dateOfHire   [1] Date,
+//		nameOfSpouse [2] Name,
+//		children     [3] IMPLICIT SEQUENCE OF ChildInformation DEFAULT {} }
+//	ChildInformation ::= SET { name Name, dateOfBirth [0] Date }
+//	Name ::= [APPLICATION 1] IMPLICIT SEQUENCE {
+//		givenName VisibleString, initial VisibleString, familyName VisibleString }
+//	EmployeeNumber ::= [APPLICATION 2] IMPLICIT INTEGER
+//	Date ::= [APPLICATION 3] IMPLICIT VisibleString
+//
+// SET components are encoded in canonical tag order.
+type personnel struct {
+	name, spouse []string
+	title, hired string
+	number       int64
+	children     []child
+}
+
+type child struct {
+	name []string
+	born string
+}
+
+func encodeName(e *Encoder, name []string) error {
+	for _, s := range name {
+		if err := e.VisibleString(s, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeName(d *Decoder) ([]string, error) {
+	out := make([]string, 3)
+	for i := range out {
+		var err error
+		if out[i], err = d.VisibleString(nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (p personnel) encode(e *Encoder) error {
+	opt := bitarray.NewOptionalSet(1)
+	opt.SetPresent(0, len(p.children) > 0)
+	e.Sequence(false, opt)
+	if err := encodeName(e, p.name); err != nil {
+		return err
+	}
+	if err := e.Integer(p.number, nil); err != nil {
+		return err
+	}
+	if err := e.VisibleString(p.title, nil); err != nil {
+		return err
+	}
+	if err := e.VisibleString(p.hired, nil); err != nil {
+		return err
+	}
+	if err := encodeName(e, p.spouse); err != nil {
+		return err
+	}
+	if len(p.children) == 0 {
+		return nil
+	}
+	if err := e.SequenceOf(len(p.children), nil); err != nil {
+		return err
+	}
+	for _, c := range p.children {
+		if err := encodeName(e, c.name); err != nil {
+			return err
+		}
+		if err := e.VisibleString(c.born, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodePersonnel(d *Decoder) (p personnel, err error) {
+	opt, _, err := d.Sequence(false, 1)
+	if err != nil {
+		return p, err
+	}
+	if p.name, err = decodeName(d); err != nil {
+		return p, err
+	}
+	if p.number, err = d.Integer(nil); err != nil {
+		return p, err
+	}
+	if p.title, err = d.VisibleString(nil); err != nil {
+		return p, err
+	}
+	if p.hired, err = d.VisibleString(nil); err != nil {
+		return p, err
+	}
+	if p.spouse, err = decodeName(d); err != nil {
+		return p, err
+	}
+	if !opt.IsPresent(0) {
+		return p, nil
+	}
+	n, err := d.SequenceOf(nil)
+	if err != nil {
+		return p, err
+	}
+	p.children = make([]child, n)
+	for i := range p.children {
+		if p.children[i].name, err = decodeName(d); err != nil {
+			return p, err
+		}
+		if p.children[i].born, err = d.VisibleString(nil); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func TestPersonnelRecord(t *testing.T) {
+	in := personnel{
+		name:   []string{"John", "P", "Smith"},
+		title:  "Director",
+		number: 51,
+		hired:  "19710917",
+		spouse: []string{"Mary", "T", "Smith"},
+		children: []child{
+			{name: []string{"Ralph", "T", "Smith"}, born: "19571111"},
+			{name: []string{"Susan", "B", "Jones"}, born: "19590717"},
+		},
+	}
+	// The unaligned encoding from X.691 A.1.3
+	expected, _ := hex.DecodeString("" +
+		"824adfa3700d005a7b74f4d0026611134f2cb8fa6fe410c5cb762c1cb16e0937" +
+		"0f2f20350169edd3d340102d2c3b386801a80b4f6e9e9a0218b96add8b162c41" +
+		"69f5e787700c20595bf765e610c5cb572c1bb16e")
+
+	e := NewEncoder(nil)
+	if err := in.encode(e); err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	if actual := e.BitArray().Bytes(); !bytes.Equal(actual, expected) {
+		t.Errorf("got %x, want %x", actual, expected)
+	}
+
+	actual, err := decodePersonnel(NewDecoder(bitarray.NewReader(bitarray.NewFromBytes(expected, int64(len(expected)*8)))))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if !reflect.DeepEqual(actual, in) {
+		t.Errorf("got %+v, want %+v", actual, in)
+	}
+}
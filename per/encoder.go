@@ -0,0 +1,308 @@
+package per
+
+import (
+	"fmt"
+	"strings"
+
+	"src.userspace.com.au/bitarray"
+)
+
+// Encoder writes UPER encodings to a BitArray.
+type Encoder struct {
+	ba *bitarray.BitArray
+}
+
+// NewEncoder creates an Encoder appending to ba. A nil ba is replaced by a
+// new empty BitArray.
+func NewEncoder(ba *bitarray.BitArray) *Encoder {
+	if ba == nil {
+		ba = bitarray.New()
+	}
+	return &Encoder{ba: ba}
+}
+
+// BitArray returns the encoded bits.
+func (e *Encoder) BitArray() *bitarray.BitArray {
+	return e.ba
+}
+
+// Bool encodes a BOOLEAN.
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.ba.AddBit(1)
+	} else {
+		e.ba.AddBit(0)
+	}
+}
+
+// Integer encodes an INTEGER with the value constraint r.
+func (e *Encoder) Integer(v int64, r *Range) error {
+	if r == nil {
+		return e.unconstrained(v)
+	}
+	if r.Lower > r.Upper {
+		return fmt.Errorf("invalid range %d..%d", r.Lower, r.Upper)
+	}
+	inRoot := v >= r.Lower && v <= r.Upper
+	if r.Extensible {
+		e.Bool(!inRoot)
+		if !inRoot {
+			return e.unconstrained(v)
+		}
+	}
+	if !inRoot {
+		return fmt.Errorf("%w: %d not in %d..%d", bitarray.ErrOutOfRange, v, r.Lower, r.Upper)
+	}
+	_, err := e.ba.AddRange(v, r.Lower, r.Upper)
+	return err
+}
+
+// SemiConstrained encodes an INTEGER with only a lower bound.
+func (e *Encoder) SemiConstrained(v, lower int64) error {
+	if v < lower {
+		return fmt.Errorf("%w: %d below %d", bitarray.ErrOutOfRange, v, lower)
+	}
+	u := uint64(v) - uint64(lower)
+	n := unsignedOctets(u)
+	if err := e.length(n); err != nil {
+		return err
+	}
+	e.ba.AddN(uint(u), n*8)
+	return nil
+}
+
+func (e *Encoder) unconstrained(v int64) error {
+	n := signedOctets(v)
+	if err := e.length(n); err != nil {
+		return err
+	}
+	u := uint64(v)
+	if n < 8 {
+		u &= 1<<uint(n*8) - 1
+	}
+	e.ba.AddN(uint(u), n*8)
+	return nil
+}
+
+// Enumerated encodes the index i of an ENUMERATED with count root values.
+// Indexes from count up are extension values and require extensible.
+func (e *Encoder) Enumerated(i, count int, extensible bool) error {
+	return e.index(i, count, extensible)
+}
+
+// Choice encodes the index i of a CHOICE with count root alternatives.
+// Indexes from count up are extension additions and require extensible; their
+// value must be encoded with OpenType.
+func (e *Encoder) Choice(i, count int, extensible bool) error {
+	return e.index(i, count, extensible)
+}
+
+func (e *Encoder) index(i, count int, extensible bool) error {
+	if i < 0 || (i >= count && !extensible) {
+		return fmt.Errorf("%w: index %d not in 0..%d", bitarray.ErrOutOfRange, i, count-1)
+	}
+	if extensible {
+		e.Bool(i >= count)
+		if i >= count {
+			return e.normallySmall(i - count)
+		}
+	}
+	_, err := e.ba.AddRange(int64(i), 0, int64(count-1))
+	return err
+}
+
+// Encode a normally small non-negative whole number.
+func (e *Encoder) normallySmall(n int) error {
+	if n < 64 {
+		e.ba.AddBit(0)
+		e.ba.AddN(uint(n), 6)
+		return nil
+	}
+	e.ba.AddBit(1)
+	return e.SemiConstrained(int64(n), 0)
+}
+
+// Encode a normally small length, which is at least one.
+func (e *Encoder) normallySmallLength(n int) error {
+	if n < 1 {
+		return fmt.Errorf("%w: length %d", bitarray.ErrOutOfRange, n)
+	}
+	if n <= 64 {
+		e.ba.AddBit(0)
+		e.ba.AddN(uint(n-1), 6)
+		return nil
+	}
+	e.ba.AddBit(1)
+	return e.length(n)
+}
+
+// Sequence encodes the preamble of a SEQUENCE: the extension bit when
+// extensible and the presence bitmap of its OPTIONAL and DEFAULT components.
+// Use ExtendedSequence when extension additions are present.
+func (e *Encoder) Sequence(extensible bool, optional *bitarray.OptionalSet) {
+	if extensible {
+		e.ba.AddBit(0)
+	}
+	if optional != nil {
+		optional.Encode(e.ba)
+	}
+}
+
+// ExtendedSequence encodes the preamble of an extensible SEQUENCE with
+// extension additions, which are encoded with Extensions after the root
+// components.
+func (e *Encoder) ExtendedSequence(optional *bitarray.OptionalSet) {
+	e.ba.AddBit(1)
+	if optional != nil {
+		optional.Encode(e.ba)
+	}
+}
+
+// Extensions encodes the extension additions of a SEQUENCE, each as an open
+// type. A nil addition is absent. At least one addition must be present.
+func (e *Encoder) Extensions(additions ...func(*Encoder) error) error {
+	present := bitarray.NewOptionalSet(len(additions))
+	count := 0
+	for i, fn := range additions {
+		if fn != nil {
+			present.SetPresent(i, true)
+			count++
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("no extension additions present")
+	}
+	if err := e.normallySmallLength(len(additions)); err != nil {
+		return err
+	}
+	present.Encode(e.ba)
+	for _, fn := range additions {
+		if fn == nil {
+			continue
+		}
+		if err := e.OpenType(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SequenceOf encodes the number of components of a SEQUENCE OF.
+func (e *Encoder) SequenceOf(n int, s *Size) error {
+	return e.sizedLength(n, s)
+}
+
+// BitString encodes a BIT STRING.
+func (e *Encoder) BitString(b *bitarray.BitArray, s *Size) error {
+	if err := e.sizedLength(int(b.Len()), s); err != nil {
+		return err
+	}
+	e.ba.Append(*b)
+	return nil
+}
+
+// OctetString encodes an OCTET STRING.
+func (e *Encoder) OctetString(b []byte, s *Size) error {
+	if err := e.sizedLength(len(b), s); err != nil {
+		return err
+	}
+	return e.ba.Pack(b)
+}
+
+// IA5String encodes an IA5String.
+func (e *Encoder) IA5String(v string, s *Size) error {
+	return e.KnownMultiplierString(v, ia5Alphabet, s)
+}
+
+// VisibleString encodes a VisibleString.
+func (e *Encoder) VisibleString(v string, s *Size) error {
+	return e.KnownMultiplierString(v, visibleAlphabet, s)
+}
+
+// NumericString encodes a NumericString.
+func (e *Encoder) NumericString(v string, s *Size) error {
+	return e.KnownMultiplierString(v, NumericAlphabet, s)
+}
+
+// PrintableString encodes a PrintableString.
+func (e *Encoder) PrintableString(v string, s *Size) error {
+	return e.KnownMultiplierString(v, PrintableAlphabet, s)
+}
+
+// KnownMultiplierString encodes a string of single byte characters from the
+// permitted alphabet, given in ascending order.
+func (e *Encoder) KnownMultiplierString(v, alphabet string, s *Size) error {
+	width, indexed := charEncoding(alphabet)
+	for i := 0; i < len(v); i++ {
+		if strings.IndexByte(alphabet, v[i]) < 0 {
+			return fmt.Errorf("%w: character %q not permitted", bitarray.ErrOutOfRange, v[i])
+		}
+	}
+	if err := e.sizedLength(len(v), s); err != nil {
+		return err
+	}
+	for i := 0; i < len(v); i++ {
+		c := uint(v[i])
+		if indexed {
+			c = uint(strings.IndexByte(alphabet, v[i]))
+		}
+		e.ba.AddN(c, width)
+	}
+	return nil
+}
+
+// OpenType encodes the result of fn as an open type, an octet aligned
+// encoding preceded by its length in octets.
+func (e *Encoder) OpenType(fn func(*Encoder) error) error {
+	inner := NewEncoder(nil)
+	if err := fn(inner); err != nil {
+		return err
+	}
+	b := inner.ba
+	if b.Len() == 0 {
+		b.AddN(0, 8)
+	}
+	if r := b.Len() % 8; r != 0 {
+		b.AddN(0, int(8-r))
+	}
+	return e.OctetString(b.Bytes(), nil)
+}
+
+// Encode a length with an optional size constraint.
+func (e *Encoder) sizedLength(n int, s *Size) error {
+	if s == nil {
+		return e.length(n)
+	}
+	if s.Min > s.Max || s.Min < 0 {
+		return fmt.Errorf("invalid size %d..%d", s.Min, s.Max)
+	}
+	inRoot := n >= s.Min && n <= s.Max
+	if s.Extensible {
+		e.Bool(!inRoot)
+		if !inRoot {
+			return e.length(n)
+		}
+	}
+	if !inRoot {
+		return fmt.Errorf("%w: size %d not in %d..%d", bitarray.ErrOutOfRange, n, s.Min, s.Max)
+	}
+	if s.Max >= maxConstrainedLength {
+		return e.length(n)
+	}
+	_, err := e.ba.AddRange(int64(n), int64(s.Min), int64(s.Max))
+	return err
+}
+
+// Encode an unconstrained length determinant.
+func (e *Encoder) length(n int) error {
+	switch {
+	case n < 128:
+		e.ba.AddN(uint(n), 8)
+	case n < maxLength:
+		e.ba.AddN(2, 2)
+		e.ba.AddN(uint(n), 14)
+	default:
+		return fmt.Errorf("%w: fragmented length %d", ErrUnsupported, n)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package per
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"src.userspace.com.au/bitarray"
+)
+
+func TestEncoder(t *testing.T) {
+	fixed4 := &Size{Min: 4, Max: 4}
+	small := &Size{Min: 0, Max: 7}
+
+	tests := map[string]struct {
+		encode   func(e *Encoder) error
+		expected string
+	}{
+		"boolean": {func(e *Encoder) error { e.Bool(true); e.Bool(false); return nil }, "10"},
+		"byteRange": {
+			func(e *Encoder) error { return e.Integer(5, &Range{Lower: 0, Upper: 255}) },
+			"00000101",
+		},
+		"smallRange": {
+			func(e *Encoder) error { return e.Integer(5, &Range{Lower: 3, Upper: 6}) },
+			"10",
+		},
+		"singleValue": {
+			func(e *Encoder) error { return e.Integer(5, &Range{Lower: 5, Upper: 5}) },
+			"",
+		},
+		"unconstrained": {
+			func(e *Encoder) error { return e.Integer(128, nil) },
+			"00000010" + "00000000" + "10000000",
+		},
+		"unconstrainedNegative": {
+			func(e *Encoder) error { return e.Integer(-128, nil) },
+			"00000001" + "10000000",
+		},
+		"unconstrainedZero": {
+			func(e *Encoder) error { return e.Integer(0, nil) },
+			"00000001" + "00000000",
+		},
+		"semiConstrained": {
+			func(e *Encoder) error { return e.SemiConstrained(256, 0) },
+			"00000010" + "00000001" + "00000000",
+		},
+		"semiConstrainedOffset": {
+			func(e *Encoder) error { return e.SemiConstrained(-1, -1) },
+			"00000001" + "00000000",
+		},
+		"extensibleRoot": {
+			func(e *Encoder) error { return e.Integer(3, &Range{Lower: 0, Upper: 7, Extensible: true}) },
+			"0" + "011",
+		},
+		"extensibleAddition": {
+			func(e *Encoder) error { return e.Integer(9, &Range{Lower: 0, Upper: 7, Extensible: true}) },
+			"1" + "00000001" + "00001001",
+		},
+		"enumerated": {
+			func(e *Encoder) error { return e.Enumerated(2, 3, false) },
+			"10",
+		},
+		"enumeratedExtension": {
+			func(e *Encoder) error { return e.Enumerated(4, 3, true) },
+			"1" + "0000001",
+		},
+		"bitStringFixed": {
+			func(e *Encoder) error { return e.BitString(bitarray.NewFromBytes([]byte{0xb0}, 4), fixed4) },
+			"1011",
+		},
+		"bitStringUnconstrained": {
+			func(e *Encoder) error { return e.BitString(bitarray.NewFromBytes([]byte{0xa0}, 3), nil) },
+			"00000011" + "101",
+		},
+		"octetStringConstrained": {
+			func(e *Encoder) error { return e.OctetString([]byte("AB"), small) },
+			"010" + "01000001" + "01000010",
+		},
+		"octetStringLong": {
+			func(e *Encoder) error { return e.OctetString(make([]byte, 200), nil) },
+			"10" + "00000011001000" + strings.Repeat("0", 1600),
+		},
+		"ia5String": {
+			func(e *Encoder) error { return e.IA5String("AB", nil) },
+			"00000010" + "1000001" + "1000010",
+		},
+		"numericString": {
+			func(e *Encoder) error { return e.NumericString("1 9", &Size{Min: 3, Max: 3}) },
+			"0010" + "0000" + "1010",
+		},
+		"permittedAlphabet": {
+			// Values fit 2 bits so are not indexed
+			func(e *Encoder) error {
+				return e.KnownMultiplierString("\x01\x03", "\x00\x01\x02\x03", &Size{Min: 2, Max: 2})
+			},
+			"01" + "11",
+		},
+		"sequence": {
+			func(e *Encoder) error {
+				o := bitarray.NewOptionalSet(2)
+				o.SetPresent(0, true)
+				e.Sequence(true, o)
+				return e.Integer(1, &Range{Lower: 0, Upper: 3})
+			},
+			"0" + "10" + "01",
+		},
+		"sequenceOf": {
+			func(e *Encoder) error {
+				if err := e.SequenceOf(2, &Size{Min: 1, Max: 4}); err != nil {
+					return err
+				}
+				e.Bool(true)
+				e.Bool(true)
+				return nil
+			},
+			"01" + "11",
+		},
+		"choice": {
+			func(e *Encoder) error { return e.Choice(1, 3, false) },
+			"01",
+		},
+		"choiceExtension": {
+			func(e *Encoder) error {
+				if err := e.Choice(3, 3, true); err != nil {
+					return err
+				}
+				return e.OpenType(func(e *Encoder) error { e.Bool(true); return nil })
+			},
+			"1" + "0000000" + "00000001" + "10000000",
+		},
+		"extensions": {
+			func(e *Encoder) error {
+				e.ExtendedSequence(nil)
+				e.Bool(true)
+				return e.Extensions(nil, func(e *Encoder) error { e.Bool(true); return nil })
+			},
+			"1" + "1" + "0000001" + "01" + "00000001" + "10000000",
+		},
+		"manyExtensions": {
+			func(e *Encoder) error {
+				additions := make([]func(*Encoder) error, 65)
+				additions[64] = func(e *Encoder) error { e.Bool(true); return nil }
+				e.ExtendedSequence(nil)
+				return e.Extensions(additions...)
+			},
+			"1" + "1" + "01000001" + strings.Repeat("0", 64) + "1" + "00000001" + "10000000",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncoder(nil)
+			if err := tt.encode(e); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			expected, _ := bitarray.NewFromString(tt.expected)
+			if actual := e.BitArray().String(); actual != expected.String() {
+				t.Errorf("got %s, want %s", actual, expected)
+			}
+		})
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	tests := map[string]struct {
+		encode func(e *Encoder) error
+		err    error
+	}{
+		"range":      {func(e *Encoder) error { return e.Integer(8, &Range{Lower: 0, Upper: 7}) }, bitarray.ErrOutOfRange},
+		"semi":       {func(e *Encoder) error { return e.SemiConstrained(-1, 0) }, bitarray.ErrOutOfRange},
+		"enumerated": {func(e *Encoder) error { return e.Enumerated(3, 3, false) }, bitarray.ErrOutOfRange},
+		"size":       {func(e *Encoder) error { return e.OctetString([]byte("abc"), &Size{Min: 0, Max: 2}) }, bitarray.ErrOutOfRange},
+		"alphabet":   {func(e *Encoder) error { return e.NumericString("12a", nil) }, bitarray.ErrOutOfRange},
+		"fragment":   {func(e *Encoder) error { return e.OctetString(make([]byte, 16384), nil) }, ErrUnsupported},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tt.encode(NewEncoder(nil)); !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package per implements the ASN.1 unaligned packed encoding rules (UPER) of
+// ITU-T X.691 on top of BitArray.
+//
+// Encoder and Decoder provide a method for each ASN.1 type. Structured types
+// are encoded by calling the methods for their components in order, after
+// Sequence, SequenceOf or Choice has written the preamble. Extension additions
+// of a SEQUENCE are encoded as open types with ExtendedSequence and Extensions
+// and decoded with Extensions.
+package per
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrUnsupported is returned for encodings this package does not implement.
+var ErrUnsupported = errors.New("unsupported encoding")
+
+// Range is a value constraint on an INTEGER. A nil *Range is unconstrained.
+type Range struct {
+	Lower, Upper int64
+	Extensible   bool
+}
+
+// Size is a SIZE constraint on a string or SEQUENCE OF. A nil *Size is
+// unconstrained.
+type Size struct {
+	Min, Max   int
+	Extensible bool
+}
+
+// Lengths from this bound up use the unconstrained length determinant.
+const maxConstrainedLength = 64 * 1024
+
+// Lengths from this bound up require fragmentation.
+const maxLength = 16 * 1024
+
+// Canonical alphabets of the known-multiplier character string types.
+const (
+	NumericAlphabet   = " 0123456789"
+	PrintableAlphabet = " '()+,-./0123456789:=?ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var ia5Alphabet = func() string {
+	b := make([]byte, 128)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return string(b)
+}()
+
+var visibleAlphabet = func() string {
+	b := make([]byte, 0, 95)
+	for i := 0x20; i < 0x7f; i++ {
+		b = append(b, byte(i))
+	}
+	return string(b)
+}()
+
+// The width of each character and whether characters are encoded by their
+// index in the alphabet rather than their value, from X.691 30.5.
+func charEncoding(alphabet string) (int, bool) {
+	width := bits.Len(uint(len(alphabet) - 1))
+	max := 0
+	for i := 0; i < len(alphabet); i++ {
+		if int(alphabet[i]) > max {
+			max = int(alphabet[i])
+		}
+	}
+	return width, max >= 1<<uint(width)
+}
+
+// The number of octets needed for v as a two's complement integer.
+func signedOctets(v int64) int {
+	if v < 0 {
+		v = ^v
+	}
+	return bits.Len64(uint64(v))/8 + 1
+}
+
+// The number of octets needed for v as a non-negative binary integer.
+func unsignedOctets(v uint64) int {
+	n := (bits.Len64(v) + 7) / 8
+	if n == 0 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,250 @@
+package bitarray
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Poly is a polynomial over GF(2). Coefficient i is bit i%64 of word i/64.
+// Polys are immutable, operations return new values.
+type Poly struct {
+	w []uint64
+}
+
+// NewPoly creates a Poly with bit i of v as the coefficient of x^i, so 0x13 is
+// x^4 + x + 1.
+func NewPoly(v uint64) Poly {
+	return Poly{w: []uint64{v}}.norm()
+}
+
+// NewPolyFromBitArray creates a Poly from the bits of ba, the first bit being
+// the highest degree coefficient.
+func NewPolyFromBitArray(ba *BitArray) Poly {
+	n := ba.Len()
+	out := Poly{w: make([]uint64, (n+63)/64)}
+	for i := int64(0); i < n; i++ {
+		if ba.Test(i) {
+			d := n - 1 - i
+			out.w[d/64] |= 1 << uint(d%64)
+		}
+	}
+	return out.norm()
+}
+
+// BitArray returns the coefficients, highest degree first, as Degree()+1
+// bits. The zero polynomial is empty.
+func (p Poly) BitArray() *BitArray {
+	out := New()
+	for d := p.Degree(); d >= 0; d-- {
+		if p.Coeff(d) {
+			out.AddBit(1)
+		} else {
+			out.AddBit(0)
+		}
+	}
+	return out
+}
+
+// Uint64 returns the coefficients of x^0 to x^63.
+func (p Poly) Uint64() uint64 {
+	if len(p.w) == 0 {
+		return 0
+	}
+	return p.w[0]
+}
+
+// Degree returns the degree, or -1 for the zero polynomial.
+func (p Poly) Degree() int {
+	if len(p.w) == 0 {
+		return -1
+	}
+	top := len(p.w) - 1
+	return top*64 + bits.Len64(p.w[top]) - 1
+}
+
+// Coeff returns true if the coefficient of x^i is 1.
+func (p Poly) Coeff(i int) bool {
+	if i < 0 || i/64 >= len(p.w) {
+		return false
+	}
+	return p.w[i/64]>>uint(i%64)&1 == 1
+}
+
+// IsZero returns true for the zero polynomial.
+func (p Poly) IsZero() bool {
+	return len(p.w) == 0
+}
+
+// Equal returns true if p and q have the same coefficients.
+func (p Poly) Equal(q Poly) bool {
+	if len(p.w) != len(q.w) {
+		return false
+	}
+	for i := range p.w {
+		if p.w[i] != q.w[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns p+q, which is also p-q.
+func (p Poly) Add(q Poly) Poly {
+	if len(p.w) < len(q.w) {
+		p, q = q, p
+	}
+	out := Poly{w: append([]uint64(nil), p.w...)}
+	for i, v := range q.w {
+		out.w[i] ^= v
+	}
+	return out.norm()
+}
+
+// Mul returns p*q using carry-less multiplication a word at a time.
+func (p Poly) Mul(q Poly) Poly {
+	if p.IsZero() || q.IsZero() {
+		return Poly{}
+	}
+	out := Poly{w: make([]uint64, len(p.w)+len(q.w))}
+	for i, a := range p.w {
+		for j, b := range q.w {
+			hi, lo := clmul(a, b)
+			out.w[i+j] ^= lo
+			out.w[i+j+1] ^= hi
+		}
+	}
+	return out.norm()
+}
+
+// DivMod returns the quotient and remainder of p/d. It panics if d is zero.
+func (p Poly) DivMod(d Poly) (Poly, Poly) {
+	dd := d.Degree()
+	if dd < 0 {
+		panic("bitarray: division by zero polynomial")
+	}
+	r := Poly{w: append([]uint64(nil), p.w...)}
+	var q Poly
+	if n := p.Degree() - dd; n >= 0 {
+		q.w = make([]uint64, n/64+1)
+	}
+	for rd := r.Degree(); rd >= dd; rd = r.degreeBelow(rd) {
+		shift := rd - dd
+		q.w[shift/64] |= 1 << uint(shift%64)
+		r.xorShifted(d, shift)
+	}
+	return q.norm(), r.norm()
+}
+
+// Mod returns p mod m. It panics if m is zero.
+func (p Poly) Mod(m Poly) Poly {
+	_, r := p.DivMod(m)
+	return r
+}
+
+// GCD returns the greatest common divisor of p and q.
+func (p Poly) GCD(q Poly) Poly {
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+	return p
+}
+
+// ExpMod returns p^e mod m. It panics if m is zero.
+func (p Poly) ExpMod(e uint64, m Poly) Poly {
+	out := NewPoly(1).Mod(m)
+	base := p.Mod(m)
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			out = out.Mul(base).Mod(m)
+		}
+		base = base.Mul(base).Mod(m)
+	}
+	return out
+}
+
+// IsIrreducible returns true if p has degree of at least 1 and no factors of
+// lower degree, using Ben-Or's test.
+func (p Poly) IsIrreducible() bool {
+	n := p.Degree()
+	if n < 1 {
+		return false
+	}
+	x := NewPoly(2)
+	u := x
+	for i := 1; i <= n/2; i++ {
+		// u = x^(2^i) mod p
+		u = u.Mul(u).Mod(p)
+		if p.GCD(u.Add(x)).Degree() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns p as a sum of terms, such as "x^4 + x + 1".
+func (p Poly) String() string {
+	if p.IsZero() {
+		return "0"
+	}
+	var terms []string
+	for d := p.Degree(); d >= 0; d-- {
+		if !p.Coeff(d) {
+			continue
+		}
+		switch d {
+		case 0:
+			terms = append(terms, "1")
+		case 1:
+			terms = append(terms, "x")
+		default:
+			terms = append(terms, "x^"+strconv.Itoa(d))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Remove high zero words.
+func (p Poly) norm() Poly {
+	n := len(p.w)
+	for n > 0 && p.w[n-1] == 0 {
+		n--
+	}
+	p.w = p.w[:n]
+	return p
+}
+
+// The degree of the highest coefficient below d, or -1.
+func (p Poly) degreeBelow(d int) int {
+	for d--; d >= 0; d-- {
+		if w := p.w[d/64] & (1<<uint(d%64+1) - 1); w != 0 {
+			return d/64*64 + bits.Len64(w) - 1
+		}
+		d = d / 64 * 64
+	}
+	return -1
+}
+
+// Add d*x^shift in place, p must have room for the result.
+func (p Poly) xorShifted(d Poly, shift int) {
+	words, s := shift/64, uint(shift%64)
+	for i, v := range d.w {
+		p.w[i+words] ^= v << s
+		if s > 0 && i+words+1 < len(p.w) {
+			p.w[i+words+1] ^= v >> (64 - s)
+		}
+	}
+}
+
+// Carry-less multiply of two words.
+func clmul(a, b uint64) (hi, lo uint64) {
+	for a != 0 {
+		i := uint(bits.TrailingZeros64(a))
+		lo ^= b << i
+		if i > 0 {
+			hi ^= b >> (64 - i)
+		}
+		a &= a - 1
+	}
+	return hi, lo
+}
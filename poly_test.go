@@ -0,0 +1,193 @@
+package bitarray
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Multiply bit by bit.
+func naivePolyMul(a, b Poly) Poly {
+	out := Poly{}
+	for i := 0; i <= a.Degree(); i++ {
+		if a.Coeff(i) {
+			out = out.Add(b.shift(i))
+		}
+	}
+	return out
+}
+
+func (p Poly) shift(n int) Poly {
+	out := Poly{w: make([]uint64, len(p.w)+n/64+1)}
+	out.xorShifted(p, n)
+	return out.norm()
+}
+
+func randPoly(rnd *rand.Rand, words int) Poly {
+	p := Poly{w: make([]uint64, words)}
+	for i := range p.w {
+		p.w[i] = rnd.Uint64()
+	}
+	return p.norm()
+}
+
+func TestPolyString(t *testing.T) {
+	tests := map[string]struct {
+		in       Poly
+		expected string
+		degree   int
+	}{
+		"zero":  {NewPoly(0), "0", -1},
+		"one":   {NewPoly(1), "1", 0},
+		"x":     {NewPoly(2), "x", 1},
+		"gf16":  {NewPoly(0x13), "x^4 + x + 1", 4},
+		"wide":  {NewPoly(1 << 63), "x^63", 63},
+		"multi": {NewPoly(1<<63 | 1).Mul(NewPoly(2)), "x^64 + x", 64},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := tt.in.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			if actual := tt.in.Degree(); actual != tt.degree {
+				t.Errorf("got degree %d, want %d", actual, tt.degree)
+			}
+		})
+	}
+}
+
+func TestPolyBitArray(t *testing.T) {
+	p := NewPolyFromBitArray(NewFromBytes([]byte{0xd0, 0x80}, 9))
+	if actual := p.String(); actual != "x^8 + x^7 + x^5 + 1" {
+		t.Errorf("got %s, want x^8 + x^7 + x^5 + 1", actual)
+	}
+	if actual := p.BitArray().String(); actual != "[11010000 1-------]" {
+		t.Errorf("got %s, want [11010000 1-------]", actual)
+	}
+	if p.Uint64() != 0x1a1 {
+		t.Errorf("got %#x, want 0x1a1", p.Uint64())
+	}
+	if actual := NewPoly(0).BitArray().Len(); actual != 0 {
+		t.Errorf("got len=%d, want 0", actual)
+	}
+	wide := NewPolyFromBitArray(NewFromBytes([]byte{0x80, 0, 0, 0, 0, 0, 0, 0, 0x01}, 72))
+	if actual := wide.String(); actual != "x^71 + 1" {
+		t.Errorf("got %s, want x^71 + 1", actual)
+	}
+}
+
+func TestPolyMul(t *testing.T) {
+	if actual := NewPoly(0x3).Mul(NewPoly(0x3)); actual.Uint64() != 0x5 {
+		t.Errorf("got %s, want x^2 + 1", actual)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		a := randPoly(rnd, 1+rnd.Intn(3))
+		b := randPoly(rnd, 1+rnd.Intn(3))
+		if actual, expected := a.Mul(b), naivePolyMul(a, b); !actual.Equal(expected) {
+			t.Errorf("got %s, want %s", actual, expected)
+		}
+		if !a.Mul(b).Equal(b.Mul(a)) {
+			t.Error("expected multiplication to commute")
+		}
+	}
+	if !NewPoly(0).Mul(NewPoly(5)).IsZero() {
+		t.Error("expected zero")
+	}
+}
+
+func TestPolyDivMod(t *testing.T) {
+	q, r := NewPoly(0x1f).DivMod(NewPoly(0x3))
+	if q.Uint64() != 0xa || r.Uint64() != 0x1 {
+		t.Errorf("got %s rem %s, want x^3 + x rem 1", q, r)
+	}
+
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		a := randPoly(rnd, 1+rnd.Intn(4))
+		d := randPoly(rnd, 1+rnd.Intn(3))
+		if d.IsZero() {
+			continue
+		}
+		q, r := a.DivMod(d)
+		if r.Degree() >= d.Degree() {
+			t.Errorf("got remainder degree %d, divisor %d", r.Degree(), d.Degree())
+		}
+		if actual := q.Mul(d).Add(r); !actual.Equal(a) {
+			t.Errorf("got q*d+r = %s, want %s", actual, a)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	NewPoly(3).Mod(NewPoly(0))
+}
+
+func TestPolyGCD(t *testing.T) {
+	a := NewPoly(0x13).Mul(NewPoly(0x7))
+	b := NewPoly(0x13).Mul(NewPoly(0x3))
+	if actual := a.GCD(b); actual.Uint64() != 0x13 {
+		t.Errorf("got %s, want x^4 + x + 1", actual)
+	}
+	if actual := NewPoly(0x13).GCD(NewPoly(0x7)); actual.Uint64() != 1 {
+		t.Errorf("got %s, want 1", actual)
+	}
+}
+
+func TestPolyExpMod(t *testing.T) {
+	x := NewPoly(2)
+	// x generates the multiplicative group of a primitive polynomial's field
+	for _, m := range []uint64{0x13, 0x11d, 0x409} {
+		p := NewPoly(m)
+		order := uint64(1)<<uint(p.Degree()) - 1
+		if actual := x.ExpMod(order, p); actual.Uint64() != 1 {
+			t.Errorf("%s: got x^%d = %s, want 1", p, order, actual)
+		}
+		if actual := x.ExpMod(order/3, p); actual.Uint64() == 1 {
+			t.Errorf("%s: got x^%d = 1", p, order/3)
+		}
+	}
+	if actual := NewPoly(0x7).ExpMod(0, NewPoly(0x13)); actual.Uint64() != 1 {
+		t.Errorf("got %s, want 1", actual)
+	}
+}
+
+func TestPolyIsIrreducible(t *testing.T) {
+	tests := map[uint64]bool{
+		0x0:   false,
+		0x1:   false,
+		0x2:   true,
+		0x3:   true,
+		0x7:   true,
+		0x5:   false,
+		0x13:  true,
+		0x1f:  true,
+		0x15:  false,
+		0x11:  false,
+		0x11b: true,
+		0x11d: true,
+		0x1d1: false,
+	}
+	for in, expected := range tests {
+		if actual := NewPoly(in).IsIrreducible(); actual != expected {
+			t.Errorf("%s: got %t, want %t", NewPoly(in), actual, expected)
+		}
+	}
+
+	// Number of irreducible polynomials of each degree
+	counts := map[int]int{4: 3, 8: 30, 12: 335}
+	for n, expected := range counts {
+		actual := 0
+		for v := uint64(1) << uint(n); v < 1<<uint(n+1); v++ {
+			if NewPoly(v).IsIrreducible() {
+				actual++
+			}
+		}
+		if actual != expected {
+			t.Errorf("degree %d: got %d irreducible, want %d", n, actual, expected)
+		}
+	}
+}
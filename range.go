@@ -0,0 +1,99 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// ErrOutOfRange is returned when a value lies outside its declared range.
+var ErrOutOfRange = errors.New("value out of range")
+
+// RangeWidth returns the number of bits needed to encode any integer in
+// [min, max] as an offset from min. A single valued range needs no bits.
+func RangeWidth(min, max int64) int {
+	if min >= max {
+		return 0
+	}
+	return bits.Len64(uint64(max) - uint64(min))
+}
+
+// AddRange adds v, known to lie in [min, max], as v - min using the minimal
+// width for the range. Returns the number of bits added.
+func (ba *BitArray) AddRange(v, min, max int64) (int, error) {
+	if min > max {
+		return 0, fmt.Errorf("invalid range [%d, %d]", min, max)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%w: %d not in [%d, %d]", ErrOutOfRange, v, min, max)
+	}
+	width := RangeWidth(min, max)
+	if width == 0 {
+		return 0, nil
+	}
+	return ba.AddNPolicy(uint(uint64(v)-uint64(min)), width, OverflowError)
+}
+
+// ReadRange reads an integer in [min, max] encoded by AddRange.
+func (r *Reader) ReadRange(min, max int64) (int64, error) {
+	if min > max {
+		return 0, fmt.Errorf("invalid range [%d, %d]", min, max)
+	}
+	width := RangeWidth(min, max)
+	if width == 0 {
+		return min, nil
+	}
+	var u uint
+	if err := r.ReadBits(&u, width); err != nil {
+		return 0, err
+	}
+	if uint64(u) > uint64(max)-uint64(min) {
+		return 0, fmt.Errorf("%w: offset %d exceeds [%d, %d]", ErrOutOfRange, u, min, max)
+	}
+	return int64(uint64(min) + uint64(u)), nil
+}
+
+// AddScaled adds the fixed point value v * 10^k, rounded to the nearest
+// integer, in the range [min * 10^k, max * 10^k].
+// Returns the number of bits added.
+func (ba *BitArray) AddScaled(v, min, max float64, k int) (int, error) {
+	iv, err := scale(v, k)
+	if err != nil {
+		return 0, err
+	}
+	imin, err := scale(min, k)
+	if err != nil {
+		return 0, err
+	}
+	imax, err := scale(max, k)
+	if err != nil {
+		return 0, err
+	}
+	return ba.AddRange(iv, imin, imax)
+}
+
+// ReadScaled reads a fixed point value encoded by AddScaled.
+func (r *Reader) ReadScaled(min, max float64, k int) (float64, error) {
+	imin, err := scale(min, k)
+	if err != nil {
+		return 0, err
+	}
+	imax, err := scale(max, k)
+	if err != nil {
+		return 0, err
+	}
+	iv, err := r.ReadRange(imin, imax)
+	if err != nil {
+		return 0, err
+	}
+	return float64(iv) / math.Pow10(k), nil
+}
+
+func scale(v float64, k int) (int64, error) {
+	s := math.Round(v * math.Pow10(k))
+	if math.IsNaN(s) || s < math.MinInt64 || s >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %g * 10^%d does not fit 64 bits", ErrOutOfRange, v, k)
+	}
+	return int64(s), nil
+}
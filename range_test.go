@@ -0,0 +1,116 @@
+package bitarray
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRangeWidth(t *testing.T) {
+	tests := map[string]struct {
+		min, max int64
+		expected int
+	}{
+		"single":    {5, 5, 0},
+		"two":       {0, 1, 1},
+		"power":     {0, 255, 8},
+		"offset":    {1, 256, 8},
+		"nonPower":  {-10, 10, 5},
+		"fullRange": {math.MinInt64, math.MaxInt64, 64},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := RangeWidth(tt.min, tt.max); actual != tt.expected {
+				t.Errorf("got %d, want %d", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddRange(t *testing.T) {
+	tests := map[string]struct {
+		v, min, max int64
+		expected    string
+		err         error
+	}{
+		"zeroOffset": {100, 100, 107, "[000-----]", nil},
+		"negative":   {-10, -10, 10, "[00000---]", nil},
+		"max":        {10, -10, 10, "[10100---]", nil},
+		"single":     {5, 5, 5, "[]", nil},
+		"below":      {-11, -10, 10, "[]", ErrOutOfRange},
+		"above":      {11, -10, 10, "[]", ErrOutOfRange},
+		"extreme":    {math.MaxInt64, math.MinInt64, math.MaxInt64, "[11111111 11111111 11111111 11111111 11111111 11111111 11111111 11111111]", nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			_, err := ba.AddRange(tt.v, tt.min, tt.max)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			if err != nil {
+				return
+			}
+			actual, err := NewReader(ba).ReadRange(tt.min, tt.max)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if actual != tt.v {
+				t.Errorf("got %d, want %d", actual, tt.v)
+			}
+		})
+	}
+}
+
+func TestReadRangeInvalid(t *testing.T) {
+	// 5 bits of ones is 31, beyond the 21 values of [-10, 10]
+	r := NewReader(NewFromBytes([]byte{0xf8}, 5))
+	if _, err := r.ReadRange(-10, 10); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want %v", err, ErrOutOfRange)
+	}
+}
+
+func TestReadRangeTruncated(t *testing.T) {
+	r := NewReader(NewFromBytes([]byte{0xff}, 3))
+	if _, err := r.ReadRange(0, 255); err != EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+}
+
+func TestAddScaled(t *testing.T) {
+	tests := map[string]struct {
+		v, min, max float64
+		k           int
+		expected    string
+		read        float64
+	}{
+		// 214 + 400 in 11 bits
+		"temperature": {21.37, -40, 85, 1, "[01001100 110-----]", 21.4},
+		// 900000 - 339249 in 21 bits
+		"latitude": {-33.9249, -90, 90, 4, "[01000100 01110011 01111---]", -33.9249},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			if _, err := ba.AddScaled(tt.v, tt.min, tt.max, tt.k); err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			actual, err := NewReader(ba).ReadScaled(tt.min, tt.max, tt.k)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if math.Abs(actual-tt.read) > 1e-9 {
+				t.Errorf("got %g, want %g", actual, tt.read)
+			}
+		})
+	}
+}
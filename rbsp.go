@@ -0,0 +1,140 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrTrailingBits is returned for malformed rbsp_trailing_bits.
+var ErrTrailingBits = errors.New("invalid rbsp trailing bits")
+
+// RBSPReader reads the raw byte sequence payload of an H.264/H.265 NAL unit,
+// with emulation prevention bytes removed. Positions reported by the embedded
+// Reader are in the RBSP, OriginalPos maps them back to the NAL unit.
+type RBSPReader struct {
+	*Reader
+	// RBSP byte offsets that followed a removed emulation prevention byte
+	removed []int64
+	// Position of the rbsp_stop_one_bit, -1 if there is none
+	stop int64
+}
+
+// NewRBSPReader creates an RBSPReader over the NAL unit payload b, removing
+// each 0x03 that follows two zero bytes.
+func NewRBSPReader(b []byte) *RBSPReader {
+	rbsp := make([]byte, 0, len(b))
+	var removed []int64
+	zeros := 0
+	for _, c := range b {
+		if zeros >= 2 && c == 0x03 {
+			removed = append(removed, int64(len(rbsp)))
+			zeros = 0
+			continue
+		}
+		rbsp = append(rbsp, c)
+		if c == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+	out := &RBSPReader{
+		Reader:  NewReader(NewFromBytes(rbsp, int64(len(rbsp)*8))),
+		removed: removed,
+		stop:    -1,
+	}
+	for i := len(rbsp) - 1; i >= 0; i-- {
+		if c := rbsp[i]; c != 0 {
+			tz := int64(0)
+			for c&1 == 0 {
+				c >>= 1
+				tz++
+			}
+			out.stop = int64(i)*8 + 7 - tz
+			break
+		}
+	}
+	return out
+}
+
+// OriginalPos returns the current position as a bit offset into the NAL unit
+// payload, including removed emulation prevention bytes.
+func (r *RBSPReader) OriginalPos() int64 {
+	pos := r.Pos()
+	idx := pos / 8
+	n := sort.Search(len(r.removed), func(i int) bool { return r.removed[i] > idx })
+	return pos + int64(n)*8
+}
+
+// ByteAligned returns true if the position is on a byte boundary.
+func (r *RBSPReader) ByteAligned() bool {
+	return r.Pos()%8 == 0
+}
+
+// ReadUE reads an unsigned Exp-Golomb coded value, ue(v).
+func (r *RBSPReader) ReadUE() (uint, error) {
+	pos := r.Pos()
+	zeros := 0
+	for {
+		var b uint
+		if err := r.ReadBits(&b, 1); err != nil {
+			return 0, err
+		}
+		if b == 1 {
+			break
+		}
+		zeros++
+		if zeros > 32 {
+			return 0, fmt.Errorf("%w: Exp-Golomb code at bit %d longer than 32 bits", ErrOverflow, pos)
+		}
+	}
+	if zeros == 0 {
+		return 0, nil
+	}
+	var u uint
+	if err := r.ReadBits(&u, zeros); err != nil {
+		return 0, err
+	}
+	return 1<<uint(zeros) - 1 + u, nil
+}
+
+// ReadSE reads a signed Exp-Golomb coded value, se(v).
+func (r *RBSPReader) ReadSE() (int, error) {
+	k, err := r.ReadUE()
+	if err != nil {
+		return 0, err
+	}
+	if k%2 == 1 {
+		return int((k + 1) / 2), nil
+	}
+	return -int(k / 2), nil
+}
+
+// MoreRBSPData returns true if there is data before the rbsp_trailing_bits,
+// as more_rbsp_data().
+func (r *RBSPReader) MoreRBSPData() bool {
+	return r.Pos() < r.stop
+}
+
+// RBSPTrailingBits reads the rbsp_stop_one_bit and the zero bits up to the
+// next byte boundary.
+func (r *RBSPReader) RBSPTrailingBits() error {
+	pos := r.Pos()
+	var b uint
+	if err := r.ReadBits(&b, 1); err != nil {
+		return err
+	}
+	if b != 1 {
+		return fmt.Errorf("%w: no stop bit at bit %d", ErrTrailingBits, pos)
+	}
+	for !r.ByteAligned() {
+		if err := r.ReadBits(&b, 1); err != nil {
+			return err
+		}
+		if b != 0 {
+			return fmt.Errorf("%w: non-zero alignment bit at bit %d", ErrTrailingBits, r.Pos()-1)
+		}
+	}
+	return nil
+}
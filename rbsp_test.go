@@ -0,0 +1,118 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRBSPReaderEmulationPrevention(t *testing.T) {
+	tests := map[string]struct {
+		in       []byte
+		expected string
+	}{
+		"none":            {[]byte{0x01, 0x02}, "[00000001 00000010]"},
+		"single":          {[]byte{0x00, 0x00, 0x03, 0x01}, "[00000000 00000000 00000001]"},
+		"repeated":        {[]byte{0x00, 0x00, 0x03, 0x00, 0x00, 0x03}, "[00000000 00000000 00000000 00000000]"},
+		"notAfterOneZero": {[]byte{0x00, 0x03, 0x00}, "[00000000 00000011 00000000]"},
+		"resetsZeros":     {[]byte{0x00, 0x00, 0x03, 0x00, 0x03}, "[00000000 00000000 00000000 00000011]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRBSPReader(tt.in)
+			if actual := r.ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRBSPReaderOriginalPos(t *testing.T) {
+	r := NewRBSPReader([]byte{0x00, 0x00, 0x03, 0x01, 0x00, 0x00, 0x03, 0x02, 0xff})
+	positions := []struct{ rbsp, original int64 }{
+		{0, 0}, {15, 15}, {16, 24}, {23, 31}, {32, 40}, {40, 56}, {47, 63},
+	}
+	for _, p := range positions {
+		r.Seek(p.rbsp, SeekStart)
+		if actual := r.OriginalPos(); actual != p.original {
+			t.Errorf("at %d: got %d, want %d", p.rbsp, actual, p.original)
+		}
+	}
+}
+
+func TestReadUE(t *testing.T) {
+	// 1 010 011 00100 00111 0001000 = 0 1 2 3 6 7
+	ba := New()
+	ba.Pack(true)
+	ba.AddN(0x2, 3)
+	ba.AddN(0x3, 3)
+	ba.AddN(0x4, 5)
+	ba.AddN(0x7, 5)
+	ba.AddN(0x8, 7)
+	r := NewRBSPReader(ba.Bytes())
+	for _, expected := range []uint{0, 1, 2, 3, 6, 7} {
+		actual, err := r.ReadUE()
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		if actual != expected {
+			t.Errorf("got %d, want %d", actual, expected)
+		}
+	}
+
+	if _, err := NewRBSPReader(make([]byte, 5)).ReadUE(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v, want %v", err, ErrOverflow)
+	}
+}
+
+func TestReadSE(t *testing.T) {
+	// ue 0 1 2 3 4 = se 0 1 -1 2 -2
+	ba := New()
+	ba.Pack(true)
+	ba.AddN(0x2, 3)
+	ba.AddN(0x3, 3)
+	ba.AddN(0x4, 5)
+	ba.AddN(0x5, 5)
+	r := NewRBSPReader(ba.Bytes())
+	for _, expected := range []int{0, 1, -1, 2, -2} {
+		actual, err := r.ReadSE()
+		if err != nil {
+			t.Fatalf("failed with %q", err)
+		}
+		if actual != expected {
+			t.Errorf("got %d, want %d", actual, expected)
+		}
+	}
+}
+
+func TestRBSPTrailingBits(t *testing.T) {
+	// Three data bits, the stop bit and alignment, then a zero byte
+	r := NewRBSPReader([]byte{0xb0, 0x00})
+	var u uint
+	for i := 0; i < 3; i++ {
+		if !r.MoreRBSPData() {
+			t.Fatalf("expected more data at %d", r.Pos())
+		}
+		r.ReadBits(&u, 1)
+	}
+	if r.MoreRBSPData() {
+		t.Error("expected no more data")
+	}
+	if err := r.RBSPTrailingBits(); err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if r.Pos() != 8 {
+		t.Errorf("got pos=%d, want 8", r.Pos())
+	}
+
+	r = NewRBSPReader([]byte{0xb4})
+	r.ReadBits(&u, 3)
+	if err := r.RBSPTrailingBits(); !errors.Is(err, ErrTrailingBits) {
+		t.Errorf("got %v, want %v", err, ErrTrailingBits)
+	}
+	r = NewRBSPReader([]byte{0xb0})
+	r.ReadBits(&u, 2)
+	if err := r.RBSPTrailingBits(); !errors.Is(err, ErrTrailingBits) {
+		t.Errorf("got %v, want %v", err, ErrTrailingBits)
+	}
+}
@@ -24,8 +24,11 @@ const (
 
 // A Reader implements the BitReader interface.
 type Reader struct {
-	ba *BitArray
-	i  int64
+	ba     *BitArray
+	i      int64
+	tracer Tracer
+	name   string
+	limit  int64
 }
 
 // NewReader creates a new Reader.
@@ -34,17 +37,23 @@ func NewReader(ba *BitArray) *Reader {
 }
 
 // ReadBits reads n bits from the BitArray into out.
+// It returns EOF if fewer than n bits remain.
 func (r *Reader) ReadBits(out *uint, n int) error {
-	if r.i >= int64(r.ba.size) {
+	ev := r.event(OpReadBits, n)
+	if r.i >= r.ba.size || int64(n) > r.remaining() {
+		r.emit(ev, EOF)
 		return EOF
 	}
 	// TODO this will have issues on 32bit systems
 	i, err := r.ba.ReadUint(r.i, int64(n))
 	if err != nil {
+		r.emit(ev, err)
 		return err
 	}
 	*out = i
 	r.i += int64(n)
+	ev.Value = i
+	r.emit(ev, nil)
 	return nil
 }
 
@@ -55,13 +64,31 @@ func (r *Reader) Pos() int64 {
 
 // ReadBit advances one bit and returns the result of a boolean AND test on it.
 func (r *Reader) ReadBit() bool {
+	ev := r.event(OpReadBit, 1)
+	if r.i >= r.ba.size {
+		r.i++
+		r.emit(ev, EOF)
+		return false
+	}
 	out := r.ba.Test(r.i)
 	r.i++
+	if out {
+		ev.Value = 1
+	}
+	r.emit(ev, nil)
 	return out
 }
 
 // Seek sets the internal pointer to position n. It returns the resulting offset.
 func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.seek(offset, whence)
+	ev := r.event(OpSeek, 0)
+	ev.Pos = pos
+	r.emit(ev, err)
+	return pos, err
+}
+
+func (r *Reader) seek(offset int64, whence int) (int64, error) {
 	switch whence {
 	case SeekStart:
 		r.i = offset
@@ -80,3 +107,11 @@ func (r *Reader) Seek(offset int64, whence int) (int64, error) {
 	}
 	return r.i, nil
 }
+
+// The number of bits left to read.
+func (r *Reader) remaining() int64 {
+	if r.i >= r.ba.size {
+		return 0
+	}
+	return r.ba.size - r.i
+}
@@ -69,3 +69,15 @@ func TestReadBits(t *testing.T) {
 		})
 	}
 }
+
+func TestReadBitsTruncated(t *testing.T) {
+	// The last byte holds 5 bits of padding that must not be read
+	r := NewReader(NewFromBytes([]byte{0xff}, 3))
+	var out uint
+	if err := r.ReadBits(&out, 8); err != EOF {
+		t.Errorf("got %v, want EOF", err)
+	}
+	if r.Pos() != 0 {
+		t.Errorf("got pos=%d, want 0", r.Pos())
+	}
+}
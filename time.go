@@ -0,0 +1,142 @@
+package bitarray
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Day is a TimeEncoding resolution of one calendar day.
+const Day = 24 * time.Hour
+
+// TimeEncoding describes a timestamp stored as an unsigned count of
+// Resolution units since Epoch in Width bits.
+//
+// Resolutions that are a whole number of days count calendar days in
+// Location, so daylight saving changes do not shift dates. Days are counted
+// from the calendar date of Epoch in its own zone. When SinceMidnight is set
+// the count starts at midnight of the timestamp's own day in Location and
+// decoded times fall on the calendar date of Epoch.
+type TimeEncoding struct {
+	// Epoch defaults to the Unix epoch when zero.
+	Epoch         time.Time
+	Resolution    time.Duration
+	Width         int
+	Location      *time.Location
+	SinceMidnight bool
+}
+
+var (
+	// ISO20248Date encodes the number of days since 1970-01-01 UTC in 16 bits.
+	ISO20248Date = TimeEncoding{Resolution: Day, Width: 16}
+	// ISO20248DateTime encodes the number of seconds since 1970-01-01
+	// 00:00:00 UTC in 32 bits.
+	ISO20248DateTime = TimeEncoding{Resolution: time.Second, Width: 32}
+)
+
+func (enc TimeEncoding) validate() error {
+	if enc.Width < 1 || enc.Width > 64 {
+		return fmt.Errorf("invalid time encoding: width %d not in 1..64", enc.Width)
+	}
+	if enc.Resolution <= 0 {
+		return fmt.Errorf("invalid time encoding: resolution %s", enc.Resolution)
+	}
+	return nil
+}
+
+func (enc TimeEncoding) location() *time.Location {
+	if enc.Location == nil {
+		return time.UTC
+	}
+	return enc.Location
+}
+
+func (enc TimeEncoding) epoch() time.Time {
+	if enc.Epoch.IsZero() {
+		return time.Unix(0, 0).In(enc.location())
+	}
+	return enc.Epoch.In(enc.location())
+}
+
+// The calendar date of the epoch, taken in the zone of Epoch itself.
+func (enc TimeEncoding) epochDate() (int, time.Month, int) {
+	if enc.Epoch.IsZero() {
+		return 1970, time.January, 1
+	}
+	return enc.Epoch.Date()
+}
+
+func (enc TimeEncoding) calendar() bool {
+	return !enc.SinceMidnight && enc.Resolution%Day == 0
+}
+
+// AddTime adds t using enc. Times before the epoch or beyond the range of the
+// encoding are rejected. Returns the number of bits added.
+func (ba *BitArray) AddTime(t time.Time, enc TimeEncoding) (int, error) {
+	if err := enc.validate(); err != nil {
+		return 0, err
+	}
+	loc := enc.location()
+	t = t.In(loc)
+	var n int64
+	switch {
+	case enc.SinceMidnight:
+		y, m, d := t.Date()
+		n = int64(t.Sub(time.Date(y, m, d, 0, 0, 0, 0, loc)) / enc.Resolution)
+	case enc.calendar():
+		y, m, d := enc.epochDate()
+		days := civilDays(t) - civilDays(time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+		if days < 0 {
+			return 0, fmt.Errorf("%w: %s before epoch", ErrOutOfRange, t)
+		}
+		n = days / int64(enc.Resolution/Day)
+	default:
+		epoch := enc.epoch()
+		if t.Before(epoch) {
+			return 0, fmt.Errorf("%w: %s before epoch", ErrOutOfRange, t)
+		}
+		d := t.Sub(epoch)
+		if d == math.MaxInt64 {
+			return 0, fmt.Errorf("%w: %s too far from epoch", ErrOverflow, t)
+		}
+		n = int64(d / enc.Resolution)
+	}
+	if uint64(n) > uint64(maxUint(enc.Width)) {
+		return 0, fmt.Errorf("%w: %s needs more than %d bits", ErrOverflow, t, enc.Width)
+	}
+	return ba.AddN(uint(n), enc.Width), nil
+}
+
+// ReadTime reads a time encoded with enc.
+func (r *Reader) ReadTime(enc TimeEncoding) (time.Time, error) {
+	if err := enc.validate(); err != nil {
+		return time.Time{}, err
+	}
+	var u uint
+	if err := r.ReadBits(&u, enc.Width); err != nil {
+		return time.Time{}, err
+	}
+	loc := enc.location()
+	y, m, d := enc.epochDate()
+	if enc.calendar() {
+		days := uint64(u) * uint64(enc.Resolution/Day)
+		if days > math.MaxInt32 {
+			return time.Time{}, fmt.Errorf("%w: %d days", ErrOverflow, days)
+		}
+		return time.Date(y, m, d+int(days), 0, 0, 0, 0, loc), nil
+	}
+	if uint64(u) > uint64(math.MaxInt64/enc.Resolution) {
+		return time.Time{}, fmt.Errorf("%w: %d units of %s", ErrOverflow, u, enc.Resolution)
+	}
+	epoch := enc.epoch()
+	if enc.SinceMidnight {
+		epoch = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+	return epoch.Add(time.Duration(u) * enc.Resolution), nil
+}
+
+// The number of days from the Unix epoch to the calendar date of t.
+func civilDays(t time.Time) int64 {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
@@ -0,0 +1,132 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddTime(t *testing.T) {
+	sast := time.FixedZone("SAST", 2*60*60)
+	west := time.FixedZone("", -60*60)
+	epoch2000 := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		in       time.Time
+		enc      TimeEncoding
+		expected uint
+		read     time.Time
+	}{
+		"date": {
+			in:       time.Date(2020, 2, 29, 13, 45, 0, 0, time.UTC),
+			enc:      ISO20248Date,
+			expected: 18321,
+			read:     time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"dateTime": {
+			in:       time.Date(2020, 2, 29, 13, 45, 10, 500, time.UTC),
+			enc:      ISO20248DateTime,
+			expected: 1582983910,
+			read:     time.Date(2020, 2, 29, 13, 45, 10, 0, time.UTC),
+		},
+		"localDate": {
+			// Still the 1st in SAST
+			in:       time.Date(2000, 1, 1, 23, 0, 0, 0, time.UTC),
+			enc:      TimeEncoding{Epoch: epoch2000, Resolution: Day, Width: 8, Location: sast},
+			expected: 1,
+			read:     time.Date(2000, 1, 2, 0, 0, 0, 0, sast),
+		},
+		"westDate": {
+			// The epoch is still 1970-01-01 west of UTC
+			in:       time.Date(2000, 1, 1, 12, 0, 0, 0, west),
+			enc:      TimeEncoding{Resolution: Day, Width: 16, Location: west},
+			expected: 10957,
+			read:     time.Date(2000, 1, 1, 0, 0, 0, 0, west),
+		},
+		"westEpoch": {
+			in:       time.Date(1970, 1, 1, 0, 0, 0, 0, west),
+			enc:      TimeEncoding{Resolution: Day, Width: 16, Location: west},
+			expected: 0,
+			read:     time.Date(1970, 1, 1, 0, 0, 0, 0, west),
+		},
+		"weeks": {
+			in:       time.Date(2000, 1, 16, 0, 0, 0, 0, time.UTC),
+			enc:      TimeEncoding{Epoch: epoch2000, Resolution: 7 * Day, Width: 8},
+			expected: 2,
+			read:     time.Date(2000, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"hours": {
+			in:       time.Date(2000, 1, 2, 1, 59, 0, 0, time.UTC),
+			enc:      TimeEncoding{Epoch: epoch2000, Resolution: time.Hour, Width: 16},
+			expected: 25,
+			read:     time.Date(2000, 1, 2, 1, 0, 0, 0, time.UTC),
+		},
+		"milliseconds": {
+			in:       epoch2000.Add(1234567 * time.Microsecond),
+			enc:      TimeEncoding{Epoch: epoch2000, Resolution: time.Millisecond, Width: 12},
+			expected: 1234,
+			read:     epoch2000.Add(1234 * time.Millisecond),
+		},
+		"sinceMidnight": {
+			in:       time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC),
+			enc:      TimeEncoding{Epoch: epoch2000, Resolution: time.Minute, Width: 11, Location: sast, SinceMidnight: true},
+			expected: 630,
+			read:     time.Date(2000, 1, 1, 10, 30, 0, 0, sast),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			n, err := ba.AddTime(tt.in, tt.enc)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if n != tt.enc.Width {
+				t.Errorf("got n=%d, want %d", n, tt.enc.Width)
+			}
+			actual, _ := ba.ReadUint(0, int64(n))
+			if actual != tt.expected {
+				t.Errorf("got %d, want %d", actual, tt.expected)
+			}
+			read, err := NewReader(ba).ReadTime(tt.enc)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if !read.Equal(tt.read) || read.Location() != tt.read.Location() {
+				t.Errorf("got %s, want %s", read, tt.read)
+			}
+		})
+	}
+}
+
+func TestAddTimeErrors(t *testing.T) {
+	tests := map[string]struct {
+		in  time.Time
+		enc TimeEncoding
+		err error
+	}{
+		"beforeEpoch":    {time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), ISO20248Date, ErrOutOfRange},
+		"beforeEpochSec": {time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), ISO20248DateTime, ErrOutOfRange},
+		"overflowDate":   {time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC), ISO20248Date, ErrOverflow},
+		"overflowSec":    {time.Date(2107, 1, 1, 0, 0, 0, 0, time.UTC), ISO20248DateTime, ErrOverflow},
+		"farFuture":      {time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC), TimeEncoding{Resolution: time.Second, Width: 64}, ErrOverflow},
+		"invalidWidth":   {time.Now(), TimeEncoding{Resolution: time.Second}, nil},
+		"invalidRes":     {time.Now(), TimeEncoding{Width: 8}, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			_, err := ba.AddTime(tt.in, tt.enc)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+			if ba.Len() != 0 {
+				t.Errorf("got len=%d, want 0", ba.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package bitarray
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReadOp identifies the Reader operation that produced a ReadEvent.
+type ReadOp int
+
+const (
+	// OpReadBits is produced by ReadBits.
+	OpReadBits ReadOp = iota
+	// OpReadBit is produced by ReadBit.
+	OpReadBit
+	// OpSeek is produced by Seek.
+	OpSeek
+)
+
+func (op ReadOp) String() string {
+	switch op {
+	case OpReadBits:
+		return "ReadBits"
+	case OpReadBit:
+		return "ReadBit"
+	case OpSeek:
+		return "Seek"
+	default:
+		return fmt.Sprintf("ReadOp(%d)", int(op))
+	}
+}
+
+// ReadEvent describes a single operation on a Reader.
+// For reads Pos is the position the read started at, for seeks it is the
+// resulting position.
+type ReadEvent struct {
+	Op    ReadOp
+	Pos   int64
+	Width int
+	Value uint
+	Name  string
+	Err   error
+}
+
+// Tracer receives an event for every read and seek on a Reader.
+type Tracer func(ev ReadEvent)
+
+// SetTracer installs t to be called after every read and seek.
+// A nil tracer disables tracing.
+func (r *Reader) SetTracer(t Tracer) {
+	r.tracer = t
+}
+
+// Field names the next operation on the Reader for tracing.
+func (r *Reader) Field(name string) *Reader {
+	r.name = name
+	return r
+}
+
+func (r *Reader) event(op ReadOp, width int) ReadEvent {
+	return ReadEvent{Op: op, Pos: r.i, Width: width}
+}
+
+func (r *Reader) emit(ev ReadEvent, err error) {
+	ev.Name = r.name
+	ev.Err = err
+	r.name = ""
+	if r.tracer != nil {
+		r.tracer(ev)
+	}
+}
+
+// Trace records the events of a Reader and renders them against the bit
+// layout of the underlying BitArray.
+type Trace struct {
+	ba     *BitArray
+	Events []ReadEvent
+}
+
+// NewTrace creates a Trace and installs it as the tracer of r.
+func NewTrace(r *Reader) *Trace {
+	t := &Trace{ba: r.ba}
+	r.SetTracer(t.Record)
+	return t
+}
+
+// Record appends an event to the trace.
+func (t *Trace) Record(ev ReadEvent) {
+	t.Events = append(t.Events, ev)
+}
+
+// String renders an annotated dump. The first line is the String() layout of
+// the BitArray and each read is shown beneath the bits it consumed.
+func (t *Trace) String() string {
+	layout := t.ba.String()
+	var s strings.Builder
+	s.WriteString(layout)
+	s.WriteByte('\n')
+	for _, ev := range t.Events {
+		if ev.Op == OpSeek {
+			fmt.Fprintf(&s, "%-*s  seek %d", len(layout), "", ev.Pos)
+		} else {
+			line := []byte(strings.Repeat(" ", len(layout)))
+			for i := ev.Pos; i < ev.Pos+int64(ev.Width) && i < t.ba.Len(); i++ {
+				if t.ba.Test(i) {
+					line[bitColumn(i)] = '1'
+				} else {
+					line[bitColumn(i)] = '0'
+				}
+			}
+			s.Write(line)
+			fmt.Fprintf(&s, "  %d+%d", ev.Pos, ev.Width)
+			if ev.Name != "" {
+				fmt.Fprintf(&s, " %s=%d", ev.Name, ev.Value)
+			} else if ev.Err == nil {
+				fmt.Fprintf(&s, " %d", ev.Value)
+			}
+		}
+		if ev.Err != nil {
+			fmt.Fprintf(&s, " error: %s", ev.Err)
+		}
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
+
+// The column of bit i in the output of String.
+func bitColumn(i int64) int {
+	return int(1 + i + i/8)
+}
@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	r := NewReader(NewFromBytes([]byte{0xf0, 0x10}, 12))
+	var events []ReadEvent
+	r.SetTracer(func(ev ReadEvent) { events = append(events, ev) })
+
+	var out uint
+	if err := r.Field("version").ReadBits(&out, 4); err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	r.ReadBit()
+	if _, err := r.Seek(8, SeekStart); err != nil {
+		t.Fatalf("failed to seek: %s", err)
+	}
+	if err := r.ReadBits(&out, 4); err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if err := r.ReadBits(&out, 4); err != EOF {
+		t.Fatalf("got %v, want EOF", err)
+	}
+	if r.ReadBit() {
+		t.Error("got true reading past the end")
+	}
+
+	expected := []ReadEvent{
+		{Op: OpReadBits, Pos: 0, Width: 4, Value: 15, Name: "version"},
+		{Op: OpReadBit, Pos: 4, Width: 1, Value: 0},
+		{Op: OpSeek, Pos: 8},
+		{Op: OpReadBits, Pos: 8, Width: 4, Value: 1},
+		{Op: OpReadBits, Pos: 12, Width: 4, Err: EOF},
+		{Op: OpReadBit, Pos: 12, Width: 1, Err: EOF},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("got %d events, want %d", len(events), len(expected))
+	}
+	for i, ev := range events {
+		if ev != expected[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, ev, expected[i])
+		}
+	}
+}
+
+func TestTraceString(t *testing.T) {
+	r := NewReader(NewFromBytes([]byte{0xf0, 0x10}, 12))
+	trace := NewTrace(r)
+	var out uint
+	r.Field("version").ReadBits(&out, 4)
+	r.ReadBits(&out, 6)
+	r.Seek(2, SeekCurrent)
+
+	expected := "" +
+		"[11110000 0001----]\n" +
+		" 1111                0+4 version=15\n" +
+		"     0000 00         4+6 0\n" +
+		"                     seek 12\n"
+	if actual := trace.String(); actual != expected {
+		t.Errorf("got\n%s\nwant\n%s", actual, expected)
+	}
+}
@@ -0,0 +1,148 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrTooLong is returned when a variable length field exceeds the data
+// available or the limit of a Reader.
+var ErrTooLong = errors.New("field too long")
+
+// SetLimit sets the maximum number of bits returned by a single variable
+// length read. Zero limits reads only by the data remaining.
+func (r *Reader) SetLimit(n int64) {
+	r.limit = n
+}
+
+// AddPrefixed adds a count of the unit sized symbols in data using lenWidth
+// bits, followed by data itself. Returns the number of bits added.
+func (ba *BitArray) AddPrefixed(data *BitArray, lenWidth, unit int) (int, error) {
+	if unit < 1 {
+		return 0, fmt.Errorf("invalid unit %d", unit)
+	}
+	if data.Len()%int64(unit) != 0 {
+		return 0, fmt.Errorf("length %d is not a multiple of %d", data.Len(), unit)
+	}
+	c, err := ba.AddNPolicy(uint(data.Len()/int64(unit)), lenWidth, OverflowError)
+	if err != nil {
+		return 0, err
+	}
+	ba.Append(*data)
+	return c + int(data.Len()), nil
+}
+
+// AddPrefixedBytes adds the length of b in bytes using lenWidth bits,
+// followed by b. Returns the number of bits added.
+func (ba *BitArray) AddPrefixedBytes(b []byte, lenWidth int) (int, error) {
+	return ba.AddPrefixed(NewFromBytes(b, int64(len(b)*8)), lenWidth, 8)
+}
+
+// ReadPrefixed reads a field written by AddPrefixed.
+func (r *Reader) ReadPrefixed(lenWidth, unit int) (*BitArray, error) {
+	if unit < 1 {
+		return nil, fmt.Errorf("invalid unit %d", unit)
+	}
+	pos := r.Pos()
+	var count uint
+	if err := r.ReadBits(&count, lenWidth); err != nil {
+		return nil, err
+	}
+	if uint64(count) > math.MaxInt64/uint64(unit) {
+		return nil, fmt.Errorf("%w: %d symbols at bit %d", ErrTooLong, count, pos)
+	}
+	n := int64(count) * int64(unit)
+	if err := r.checkLen(n); err != nil {
+		return nil, fmt.Errorf("%w at bit %d", err, pos)
+	}
+	return r.readSlice(n)
+}
+
+// AddTerminated adds data as unit sized symbols followed by the terminating
+// symbol term. Data must not contain term. Returns the number of bits added.
+func (ba *BitArray) AddTerminated(data *BitArray, unit int, term uint) (int, error) {
+	if unit < 1 {
+		return 0, fmt.Errorf("invalid unit %d", unit)
+	}
+	if data.Len()%int64(unit) != 0 {
+		return 0, fmt.Errorf("length %d is not a multiple of %d", data.Len(), unit)
+	}
+	if term > maxUint(unit) {
+		return 0, fmt.Errorf("%w: terminator %d needs more than %d bits", ErrOverflow, term, unit)
+	}
+	for i := int64(0); i < data.Len(); i += int64(unit) {
+		s, err := data.ReadUint(i, int64(unit))
+		if err != nil {
+			return 0, err
+		}
+		if s == term {
+			return 0, fmt.Errorf("data contains terminator at bit %d", i)
+		}
+	}
+	ba.Append(*data)
+	return int(data.Len()) + ba.AddN(term, unit), nil
+}
+
+// ReadTerminated reads unit sized symbols up to the terminating symbol term.
+// The terminator is consumed but not returned.
+func (r *Reader) ReadTerminated(unit int, term uint) (*BitArray, error) {
+	if unit < 1 {
+		return nil, fmt.Errorf("invalid unit %d", unit)
+	}
+	pos := r.Pos()
+	var n int64
+	for {
+		if r.remaining()-n < int64(unit) {
+			return nil, fmt.Errorf("%w: no terminator after bit %d", EOF, pos)
+		}
+		s, err := r.ba.ReadUint(pos+n, int64(unit))
+		if err != nil {
+			return nil, err
+		}
+		if s == term {
+			break
+		}
+		n += int64(unit)
+		if r.limit > 0 && n > r.limit {
+			return nil, fmt.Errorf("%w: more than %d bits at bit %d", ErrTooLong, r.limit, pos)
+		}
+	}
+	out, err := r.readSlice(n)
+	if err != nil {
+		return nil, err
+	}
+	var t uint
+	if err := r.ReadBits(&t, unit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Ensure n bits can be read within the data and limit.
+func (r *Reader) checkLen(n int64) error {
+	if r.limit > 0 && n > r.limit {
+		return fmt.Errorf("%w: %d bits exceeds limit of %d", ErrTooLong, n, r.limit)
+	}
+	if avail := r.remaining(); n > avail {
+		return fmt.Errorf("%w: %d bits exceeds %d available", ErrTooLong, n, avail)
+	}
+	return nil
+}
+
+// Read the next n bits as a new BitArray.
+func (r *Reader) readSlice(n int64) (*BitArray, error) {
+	ev := r.event(OpReadBits, int(n))
+	if n == 0 {
+		r.emit(ev, nil)
+		return New(), nil
+	}
+	out, err := r.ba.Slice(r.i, n)
+	if err != nil {
+		r.emit(ev, err)
+		return nil, err
+	}
+	r.i += n
+	r.emit(ev, nil)
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddPrefixed(t *testing.T) {
+	tests := map[string]struct {
+		data     *BitArray
+		lenWidth int
+		unit     int
+		expected string
+	}{
+		"bits":   {NewFromBytes([]byte{0xa0}, 3), 4, 1, "[0011101-]"},
+		"bytes":  {NewFromBytes([]byte{0x41, 0x42}, 16), 3, 8, "[01001000 00101000 010-----]"},
+		"nibble": {NewFromBytes([]byte{0x12}, 8), 2, 4, "[10000100 10------]"},
+		"empty":  {New(), 5, 8, "[00000---]"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ba := New()
+			n, err := ba.AddPrefixed(tt.data, tt.lenWidth, tt.unit)
+			if err != nil {
+				t.Fatalf("failed with %q", err)
+			}
+			if actual := ba.String(); actual != tt.expected {
+				t.Errorf("got %s, want %s", actual, tt.expected)
+			}
+			if int64(n) != ba.Len() {
+				t.Errorf("got n=%d, want %d", n, ba.Len())
+			}
+			actual, err := NewReader(ba).ReadPrefixed(tt.lenWidth, tt.unit)
+			if err != nil {
+				t.Fatalf("failed to read: %s", err)
+			}
+			if actual.String() != tt.data.String() {
+				t.Errorf("got %s, want %s", actual, tt.data)
+			}
+		})
+	}
+}
+
+func TestAddPrefixedErrors(t *testing.T) {
+	if _, err := New().AddPrefixedBytes(make([]byte, 8), 3); !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v, want %v", err, ErrOverflow)
+	}
+	if _, err := New().AddPrefixed(NewFromBytes([]byte{0}, 7), 4, 8); err == nil {
+		t.Error("expected error for partial unit")
+	}
+}
+
+func TestReadPrefixedLimits(t *testing.T) {
+	// A 16 bit count of 0xffff bytes with only one byte of data
+	ba := NewFromBytes([]byte{0xff, 0xff, 0x41}, 24)
+	if _, err := NewReader(ba).ReadPrefixed(16, 8); !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %v, want %v", err, ErrTooLong)
+	}
+	// Huge unit overflowing the bit count
+	if _, err := NewReader(ba).ReadPrefixed(16, 1<<62); !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %v, want %v", err, ErrTooLong)
+	}
+
+	ba = New()
+	ba.AddPrefixedBytes([]byte("abc"), 8)
+	r := NewReader(ba)
+	r.SetLimit(16)
+	if _, err := r.ReadPrefixed(8, 8); !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %v, want %v", err, ErrTooLong)
+	}
+}
+
+func TestAddTerminated(t *testing.T) {
+	ba := New()
+	ba.AddBit(1)
+	if _, err := ba.AddTerminated(NewFromBytes([]byte("hi"), 16), 8, 0); err != nil {
+		t.Fatalf("failed with %q", err)
+	}
+	if expected := "[10110100 00110100 10000000 0-------]"; ba.String() != expected {
+		t.Errorf("got %s, want %s", ba.String(), expected)
+	}
+
+	r := NewReader(ba)
+	r.ReadBit()
+	actual, err := r.ReadTerminated(8, 0)
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if string(actual.Bytes()) != "hi" {
+		t.Errorf("got %q, want %q", actual.Bytes(), "hi")
+	}
+	if r.Pos() != ba.Len() {
+		t.Errorf("got pos=%d, want %d", r.Pos(), ba.Len())
+	}
+}
+
+func TestTerminatedErrors(t *testing.T) {
+	if _, err := New().AddTerminated(NewFromBytes([]byte{0x1f}, 8), 4, 0xf); err == nil {
+		t.Error("expected error for data containing terminator")
+	}
+	if _, err := New().AddTerminated(New(), 4, 0x1f); !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v, want %v", err, ErrOverflow)
+	}
+	if _, err := NewReader(NewFromBytes([]byte{0x12, 0x34}, 16)).ReadTerminated(4, 0xf); !errors.Is(err, EOF) {
+		t.Errorf("got %v, want %v", err, EOF)
+	}
+	r := NewReader(NewFromBytes([]byte{0x12, 0x34, 0x5f}, 24))
+	r.SetLimit(8)
+	if _, err := r.ReadTerminated(4, 0xf); !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %v, want %v", err, ErrTooLong)
+	}
+}